@@ -5,13 +5,62 @@ import (
 	"net/http"
 	"slices"
 	"time"
+
+	"golang.org/x/mod/module"
 )
 
+// AuthScope restricts the requests that receive the credentials of an authentication transport.
+//
+// The credentials are never sent:
+//   - to a host that is not the host of the original request of a redirect chain.
+//   - over plain HTTP, except to the hosts matching Insecure.
+type AuthScope struct {
+	// Hosts restricts the hosts (with or without port) allowed to receive the credentials.
+	// If empty, all the hosts are allowed.
+	Hosts []string
+
+	// Insecure is a comma-separated list of glob patterns (in the syntax of GOINSECURE)
+	// of hosts allowed to receive the credentials over plain HTTP (e.g. "corp.example,*.internal").
+	Insecure string
+}
+
+// Allows reports whether the request can receive the credentials.
+func (s AuthScope) Allows(req *http.Request) bool {
+	if isCrossHostRedirect(req) {
+		return false
+	}
+
+	switch req.URL.Scheme {
+	case "https":
+	case "http":
+		if !module.MatchPrefixPatterns(s.Insecure, req.URL.Hostname()) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	return len(s.Hosts) == 0 || slices.Contains(s.Hosts, req.URL.Host) || slices.Contains(s.Hosts, req.URL.Hostname())
+}
+
+// isCrossHostRedirect reports whether the request follows a redirect from a request to another host.
+func isCrossHostRedirect(req *http.Request) bool {
+	for r := req; r.Response != nil && r.Response.Request != nil; r = r.Response.Request {
+		if r.Response.Request.URL.Host != req.URL.Host {
+			return true
+		}
+	}
+
+	return false
+}
+
 // BasicAuthTransport HTTP transport for API authentication.
 type BasicAuthTransport struct {
 	username string
 	password string
 
+	AuthScope
+
 	// Transport is the underlying HTTP transport to use when making requests.
 	// It will default to http.DefaultTransport if nil.
 	Transport http.RoundTripper
@@ -28,14 +77,12 @@ func NewBasicAuthTransport(username, password string) (*BasicAuthTransport, erro
 
 // RoundTrip executes a single HTTP transaction.
 func (t *BasicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	enrichedReq := &http.Request{}
-	*enrichedReq = *req
-
-	enrichedReq.Header = make(http.Header, len(req.Header))
-	for k, s := range req.Header {
-		enrichedReq.Header[k] = slices.Clone(s)
+	if !t.Allows(req) {
+		return t.transport().RoundTrip(req)
 	}
 
+	enrichedReq := cloneRequest(req)
+
 	if t.username != "" && t.password != "" {
 		enrichedReq.SetBasicAuth(t.username, t.password)
 	}
@@ -68,3 +115,85 @@ func (t *BasicAuthTransport) transport() http.RoundTripper {
 
 	return http.DefaultTransport
 }
+
+// NetrcTransport HTTP transport for API authentication with the credentials of a .netrc file.
+// The credentials are selected by request host: the machine matching the host (with or without port),
+// or the default entry.
+type NetrcTransport struct {
+	netrc *Netrc
+
+	AuthScope
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// NewNetrcTransport Creates a new NetrcTransport.
+func NewNetrcTransport(netrc *Netrc) (*NetrcTransport, error) {
+	if netrc == nil || (len(netrc.Machines) == 0 && netrc.Default == nil) {
+		return nil, errors.New("credentials missing")
+	}
+
+	return &NetrcTransport{netrc: netrc}, nil
+}
+
+// RoundTrip executes a single HTTP transaction.
+func (t *NetrcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.Allows(req) {
+		return t.transport().RoundTrip(req)
+	}
+
+	machine := t.netrc.Machine(req.URL.Host)
+	if machine == nil || machine == t.netrc.Default {
+		machine = t.netrc.Machine(req.URL.Hostname())
+	}
+
+	if machine == nil || (machine.Login == "" && machine.Password == "") {
+		return t.transport().RoundTrip(req)
+	}
+
+	enrichedReq := cloneRequest(req)
+	enrichedReq.SetBasicAuth(machine.Login, machine.Password)
+
+	return t.transport().RoundTrip(enrichedReq)
+}
+
+// Wrap Wraps an HTTP client Transport with the NetrcTransport.
+func (t *NetrcTransport) Wrap(client *http.Client) *http.Client {
+	backup := client.Transport
+
+	t.Transport = backup
+	client.Transport = t
+
+	return client
+}
+
+// Client Creates a new HTTP client.
+func (t *NetrcTransport) Client() *http.Client {
+	return &http.Client{
+		Transport: t,
+		Timeout:   30 * time.Second,
+	}
+}
+
+func (t *NetrcTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+
+	return http.DefaultTransport
+}
+
+// cloneRequest returns a shallow copy of the request with a deep copy of the headers.
+func cloneRequest(req *http.Request) *http.Request {
+	enrichedReq := &http.Request{}
+	*enrichedReq = *req
+
+	enrichedReq.Header = make(http.Header, len(req.Header))
+	for k, s := range req.Header {
+		enrichedReq.Header[k] = slices.Clone(s)
+	}
+
+	return enrichedReq
+}
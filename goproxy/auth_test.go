@@ -1,8 +1,11 @@
 package goproxy
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,6 +37,8 @@ func TestTokenTransport_RoundTrip(t *testing.T) {
 	transport, err := NewBasicAuthTransport(username, password)
 	require.NoError(t, err)
 
+	transport.Insecure = "example.com"
+
 	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com", nil)
 
 	resp, err := transport.RoundTrip(req)
@@ -46,3 +51,134 @@ func TestTokenTransport_RoundTrip(t *testing.T) {
 	assert.Equal(t, "user", user)
 	assert.Equal(t, "secret", pass)
 }
+
+func TestNewNetrcTransport_missing_credentials(t *testing.T) {
+	transport, err := NewNetrcTransport(&Netrc{})
+	require.Error(t, err)
+	assert.Nil(t, transport)
+}
+
+func TestNetrcTransport_RoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		user, pass, _ := req.BasicAuth()
+		_, _ = fmt.Fprint(rw, user+":"+pass)
+	}))
+	t.Cleanup(server.Close)
+
+	nrc, err := ParseNetrc(strings.NewReader("machine 127.0.0.1 login user password secret\ndefault login anonymous password none\n"))
+	require.NoError(t, err)
+
+	transport, err := NewNetrcTransport(nrc)
+	require.NoError(t, err)
+
+	transport.Insecure = "127.0.0.1,localhost"
+
+	client := transport.Client()
+
+	testCases := []struct {
+		desc     string
+		url      string
+		expected string
+	}{
+		{
+			desc:     "machine",
+			url:      server.URL,
+			expected: "user:secret",
+		},
+		{
+			desc:     "default",
+			url:      strings.Replace(server.URL, "127.0.0.1", "localhost", 1),
+			expected: "anonymous:none",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, test.url, nil)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+
+			defer func() { _ = resp.Body.Close() }()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(body))
+		})
+	}
+}
+
+func TestAuthScope_Allows(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		scope    AuthScope
+		url      string
+		redirect string
+		expected bool
+	}{
+		{
+			desc:     "https",
+			url:      "https://example.com/foo",
+			expected: true,
+		},
+		{
+			desc: "http",
+			url:  "http://example.com/foo",
+		},
+		{
+			desc:     "http: insecure host",
+			scope:    AuthScope{Insecure: "*.example.com"},
+			url:      "http://proxy.example.com/foo",
+			expected: true,
+		},
+		{
+			desc:  "http: other host",
+			scope: AuthScope{Insecure: "*.example.com"},
+			url:   "http://example.org/foo",
+		},
+		{
+			desc: "unsupported scheme",
+			url:  "ftp://example.com/foo",
+		},
+		{
+			desc:     "allowed host",
+			scope:    AuthScope{Hosts: []string{"example.com"}},
+			url:      "https://example.com:8443/foo",
+			expected: true,
+		},
+		{
+			desc:  "not allowed host",
+			scope: AuthScope{Hosts: []string{"example.com"}},
+			url:   "https://example.org/foo",
+		},
+		{
+			desc:     "redirect to the same host",
+			url:      "https://example.com/bar",
+			redirect: "https://example.com/foo",
+			expected: true,
+		},
+		{
+			desc:     "redirect to another host",
+			url:      "https://storage.example.org/bar",
+			redirect: "https://example.com/foo",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, test.url, nil)
+			require.NoError(t, err)
+
+			if test.redirect != "" {
+				origin, err := http.NewRequestWithContext(t.Context(), http.MethodGet, test.redirect, nil)
+				require.NoError(t, err)
+
+				req.Response = &http.Response{StatusCode: http.StatusFound, Request: origin}
+			}
+
+			assert.Equal(t, test.expected, test.scope.Allows(req))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package goproxy
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// DefaultBatchConcurrency is the default maximum number of concurrent requests of a batch.
+const DefaultBatchConcurrency = 8
+
+// BatchResult is the result of a batch for a module version.
+type BatchResult[T any] struct {
+	// Module is the module version requested (the version is empty for the latest version).
+	Module module.Version
+
+	Value T
+	Err   error
+}
+
+// GetInfoBatch gets information about module versions.
+// See [Client.GetInfoBatchWithContext].
+func (c *Client) GetInfoBatch(versions []module.Version) []BatchResult[*VersionInfo] {
+	return c.GetInfoBatchWithContext(context.Background(), versions)
+}
+
+// GetInfoBatchWithContext gets information about module versions.
+//
+// The requests are concurrent (limited by [Client.BatchConcurrency]),
+// and the identical in-flight requests are deduplicated.
+// The results are in the same order as the module versions.
+// When the context is canceled, the remaining module versions fail with the error of the context.
+func (c *Client) GetInfoBatchWithContext(ctx context.Context, versions []module.Version) []BatchResult[*VersionInfo] {
+	return batch(ctx, c.batchConcurrency(), versions, func(ctx context.Context, mv module.Version) (*VersionInfo, error) {
+		info, err := c.infoFlights.do(ctx, "info:"+mv.String(), func(ctx context.Context) (*VersionInfo, error) {
+			return c.GetInfoWithContext(ctx, mv.Path, mv.Version)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return copyInfo(info), nil
+	})
+}
+
+// GetLatestBatch gets information about the latest versions of modules.
+// See [Client.GetLatestBatchWithContext].
+func (c *Client) GetLatestBatch(moduleNames []string) []BatchResult[*VersionInfo] {
+	return c.GetLatestBatchWithContext(context.Background(), moduleNames)
+}
+
+// GetLatestBatchWithContext gets information about the latest versions of modules.
+//
+// The requests are concurrent (limited by [Client.BatchConcurrency]),
+// and the identical in-flight requests are deduplicated.
+// The results are in the same order as the module names.
+// When the context is canceled, the remaining modules fail with the error of the context.
+func (c *Client) GetLatestBatchWithContext(ctx context.Context, moduleNames []string) []BatchResult[*VersionInfo] {
+	modules := make([]module.Version, 0, len(moduleNames))
+	for _, name := range moduleNames {
+		modules = append(modules, module.Version{Path: name})
+	}
+
+	return batch(ctx, c.batchConcurrency(), modules, func(ctx context.Context, mv module.Version) (*VersionInfo, error) {
+		info, err := c.infoFlights.do(ctx, "latest:"+mv.Path, func(ctx context.Context) (*VersionInfo, error) {
+			return c.GetLatestWithContext(ctx, mv.Path)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return copyInfo(info), nil
+	})
+}
+
+// GetModFileBatch gets the go.mod files of module versions.
+// See [Client.GetModFileBatchWithContext].
+func (c *Client) GetModFileBatch(versions []module.Version) []BatchResult[*modfile.File] {
+	return c.GetModFileBatchWithContext(context.Background(), versions)
+}
+
+// GetModFileBatchWithContext gets the go.mod files of module versions.
+//
+// The requests are concurrent (limited by [Client.BatchConcurrency]),
+// and the identical in-flight requests are deduplicated.
+// The results are in the same order as the module versions.
+// When the context is canceled, the remaining module versions fail with the error of the context.
+func (c *Client) GetModFileBatchWithContext(ctx context.Context, versions []module.Version) []BatchResult[*modfile.File] {
+	return batch(ctx, c.batchConcurrency(), versions, func(ctx context.Context, mv module.Version) (*modfile.File, error) {
+		raw, err := c.getRawModFileShared(ctx, mv)
+		if err != nil {
+			return nil, err
+		}
+
+		// The file is parsed for each result: a modfile.File is mutable.
+		return modfile.Parse("go.mod", raw, nil)
+	})
+}
+
+// GetRawModFileBatch gets the contents of the go.mod files of module versions.
+// See [Client.GetRawModFileBatchWithContext].
+func (c *Client) GetRawModFileBatch(versions []module.Version) []BatchResult[[]byte] {
+	return c.GetRawModFileBatchWithContext(context.Background(), versions)
+}
+
+// GetRawModFileBatchWithContext gets the contents of the go.mod files of module versions.
+//
+// The requests are concurrent (limited by [Client.BatchConcurrency]),
+// and the identical in-flight requests are deduplicated.
+// The results are in the same order as the module versions.
+// When the context is canceled, the remaining module versions fail with the error of the context.
+func (c *Client) GetRawModFileBatchWithContext(ctx context.Context, versions []module.Version) []BatchResult[[]byte] {
+	return batch(ctx, c.batchConcurrency(), versions, func(ctx context.Context, mv module.Version) ([]byte, error) {
+		raw, err := c.getRawModFileShared(ctx, mv)
+		if err != nil {
+			return nil, err
+		}
+
+		return slices.Clone(raw), nil
+	})
+}
+
+// getRawModFileShared gets the content of a go.mod file, deduplicating the identical in-flight requests.
+// The content is shared: it must not be modified.
+func (c *Client) getRawModFileShared(ctx context.Context, mv module.Version) ([]byte, error) {
+	return c.modFlights.do(ctx, "mod:"+mv.String(), func(ctx context.Context) ([]byte, error) {
+		return c.GetRawModFileWithContext(ctx, mv.Path, mv.Version)
+	})
+}
+
+func (c *Client) batchConcurrency() int {
+	if c.BatchConcurrency > 0 {
+		return c.BatchConcurrency
+	}
+
+	return DefaultBatchConcurrency
+}
+
+// batch calls fetch for each module version, with a limited number of concurrent calls.
+func batch[T any](ctx context.Context, limit int, versions []module.Version, fetch func(ctx context.Context, mv module.Version) (T, error)) []BatchResult[T] {
+	results := make([]BatchResult[T], len(versions))
+
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+
+	for i, mv := range versions {
+		results[i].Module = mv
+
+		if ctx.Err() != nil {
+			results[i].Err = ctx.Err()
+
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+
+			continue
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			results[i].Value, results[i].Err = fetch(ctx, mv)
+		})
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// copyInfo copies the information shared by deduplicated requests.
+func copyInfo(info *VersionInfo) *VersionInfo {
+	cp := *info
+
+	if info.Origin != nil {
+		origin := *info.Origin
+		cp.Origin = &origin
+	}
+
+	return &cp
+}
@@ -0,0 +1,217 @@
+package goproxy
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+)
+
+func TestClient_GetInfoBatch(t *testing.T) {
+	var calls, inFlight, maxInFlight atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			highest := maxInFlight.Load()
+			if current <= highest || maxInFlight.CompareAndSwap(highest, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		switch req.URL.Path {
+		case "/github.com/ldez/grignotin/@v/v0.1.0.info":
+			_, _ = fmt.Fprint(rw, `{"Version":"v0.1.0","Time":"2024-11-12T19:41:09Z"}`)
+
+		case "/github.com/ldez/grignotin/@v/v0.2.0.info":
+			_, _ = fmt.Fprint(rw, `{"Version":"v0.2.0","Time":"2024-11-12T19:41:09Z"}`)
+
+		case "/github.com/ldez/grignotin/@latest":
+			_, _ = fmt.Fprint(rw, `{"Version":"v0.2.0","Time":"2024-11-12T19:41:09Z"}`)
+
+		default:
+			http.NotFound(rw, req)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New(WithProxyURL(server.URL), WithBatchConcurrency(2))
+	require.NoError(t, err)
+
+	versions := []module.Version{
+		{Path: "github.com/ldez/grignotin", Version: "v0.1.0"},
+		{Path: "github.com/ldez/grignotin", Version: "v0.1.0"},
+		{Path: "github.com/ldez/grignotin", Version: "v0.2.0"},
+		{Path: "github.com/ldez/grignotin", Version: "v0.3.0"},
+	}
+
+	results := client.GetInfoBatch(versions)
+	require.Len(t, results, len(versions))
+
+	for i, result := range results {
+		assert.Equal(t, versions[i], result.Module)
+	}
+
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "v0.1.0", results[0].Value.Version)
+
+	require.NoError(t, results[1].Err)
+	assert.Equal(t, "v0.1.0", results[1].Value.Version)
+
+	require.NoError(t, results[2].Err)
+	assert.Equal(t, "v0.2.0", results[2].Value.Version)
+
+	require.ErrorIs(t, results[3].Err, fs.ErrNotExist)
+
+	// The duplicated module version is requested once.
+	assert.Equal(t, int64(3), calls.Load())
+	assert.LessOrEqual(t, maxInFlight.Load(), int64(2))
+
+	latest := client.GetLatestBatch([]string{"github.com/ldez/grignotin", "github.com/ldez/unknown"})
+	require.Len(t, latest, 2)
+
+	require.NoError(t, latest[0].Err)
+	assert.Equal(t, "v0.2.0", latest[0].Value.Version)
+
+	require.ErrorIs(t, latest[1].Err, fs.ErrNotExist)
+}
+
+func TestClient_GetModFileBatch(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.mod",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(rw, "module github.com/ldez/grignotin\n\ngo 1.22\n")
+		})
+
+	client := NewClient(server.URL)
+
+	results := client.GetModFileBatch([]module.Version{
+		{Path: "github.com/ldez/grignotin", Version: "v0.1.0"},
+		{Path: "github.com/ldez/grignotin", Version: "v0.1.0"},
+	})
+	require.Len(t, results, 2)
+
+	for _, result := range results {
+		require.NoError(t, result.Err)
+		assert.Equal(t, "github.com/ldez/grignotin", result.Value.Module.Mod.Path)
+	}
+
+	// Each result has its own file.
+	assert.NotSame(t, results[0].Value, results[1].Value)
+}
+
+func TestClient_GetInfoBatch_canceled(t *testing.T) {
+	client := NewClient("https://example.com")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	results := client.GetInfoBatchWithContext(ctx, []module.Version{
+		{Path: "github.com/ldez/grignotin", Version: "v0.1.0"},
+		{Path: "github.com/ldez/grignotin", Version: "v0.2.0"},
+	})
+	require.Len(t, results, 2)
+
+	for _, result := range results {
+		require.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
+
+func Test_flightGroup(t *testing.T) {
+	var group flightGroup[int]
+
+	var calls atomic.Int64
+
+	release := make(chan struct{})
+
+	fn := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+
+	results := make([]int, 5)
+
+	for i := range results {
+		wg.Go(func() {
+			v, err := group.do(t.Context(), "key", fn)
+			assert.NoError(t, err)
+
+			results[i] = v
+		})
+	}
+
+	// Waits for all the callers.
+	require.Eventually(t, func() bool {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+
+		return group.calls["key"] != nil && group.calls["key"].waiters == len(results)
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []int{42, 42, 42, 42, 42}, results)
+	assert.Equal(t, int64(1), calls.Load())
+}
+
+func Test_flightGroup_canceled(t *testing.T) {
+	var group flightGroup[int]
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	_, err := group.do(ctx, "key", func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+
+		stopped <- ctx.Err()
+
+		return 0, ctx.Err()
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The shared call is canceled when there is no more caller.
+	select {
+	case errCall := <-stopped:
+		require.ErrorIs(t, errCall, context.Canceled)
+
+	case <-time.After(time.Second):
+		t.Fatal("the shared call is not canceled")
+	}
+
+	// A new call is not affected by the canceled call.
+	v, err := group.do(t.Context(), "key", func(context.Context) (int, error) { return 1, nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, v)
+}
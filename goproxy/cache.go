@@ -0,0 +1,311 @@
+package goproxy
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ldez/grignotin/internal/fsutil"
+	"github.com/ldez/grignotin/internal/modver"
+	"golang.org/x/mod/module"
+)
+
+// DefaultCacheTTL is the default time to live of the mutable resources (list and latest version) inside a cache.
+const DefaultCacheTTL = time.Minute
+
+// Cache stores the responses of the proxies.
+// The keys are the URLs of the resources.
+// The implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the entry of the key.
+	Get(key string) (*CacheEntry, bool)
+
+	// Set stores the entry of the key.
+	Set(key string, entry *CacheEntry)
+}
+
+// CacheEntry is a cached response.
+type CacheEntry struct {
+	Data []byte
+
+	// ETag and LastModified are the validators used to revalidate the entry (If-None-Match, If-Modified-Since).
+	ETag         string
+	LastModified string
+
+	// Expires is the time after which the entry must be revalidated.
+	// The zero value means the entry never expires (immutable resource).
+	Expires time.Time
+}
+
+func (e *CacheEntry) fresh(now time.Time) bool {
+	return e.Expires.IsZero() || now.Before(e.Expires)
+}
+
+// fetchHTTPCached gets a resource of an HTTP proxy through the cache.
+//
+// The .info and .mod files of a canonical version are immutable: they are cached forever.
+// The other resources (list, latest, queries) are cached for CacheTTL, and then revalidated.
+func (c *Client) fetchHTTPCached(ctx context.Context, endpoint *url.URL) (io.ReadCloser, error) {
+	key := endpoint.String()
+
+	now := time.Now()
+
+	cached, ok := c.Cache.Get(key)
+	if ok && cached.fresh(now) {
+		return io.NopCloser(bytes.NewReader(cached.Data)), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		setValidators(req, cached)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		entry := *cached
+		entry.Expires = now.Add(c.cacheTTL())
+
+		c.Cache.Set(key, &entry)
+
+		return io.NopCloser(bytes.NewReader(entry.Data)), nil
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return nil, handleError(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &CacheEntry{
+		Data:         data,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if !isImmutable(endpoint) {
+		entry.Expires = now.Add(c.cacheTTL())
+	}
+
+	c.Cache.Set(key, entry)
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// setValidators makes the request conditional: the proxy can respond 304 (Not Modified) to revalidate the entry.
+func setValidators(req *http.Request, entry *CacheEntry) {
+	if entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	if entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+}
+
+func (c *Client) cacheTTL() time.Duration {
+	if c.CacheTTL > 0 {
+		return c.CacheTTL
+	}
+
+	return DefaultCacheTTL
+}
+
+// isImmutable reports whether the resource is the .info, .mod, or .zip file of a canonical version.
+func isImmutable(endpoint *url.URL) bool {
+	dir, file := path.Split(endpoint.Path)
+	if !strings.HasSuffix(dir, "/@v/") {
+		return false
+	}
+
+	ext := path.Ext(file)
+	if ext != ".info" && ext != ".mod" && ext != ".zip" {
+		return false
+	}
+
+	version, err := module.UnescapeVersion(strings.TrimSuffix(file, ext))
+	if err != nil {
+		return false
+	}
+
+	return modver.IsCanonical(version)
+}
+
+// MemoryCache is an in-memory LRU Cache, limited by the total size of the data.
+type MemoryCache struct {
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache Creates a new MemoryCache.
+// The least recently used entries are evicted when the total size of the data exceeds maxSize (in bytes).
+func NewMemoryCache(maxSize int64) *MemoryCache {
+	return &MemoryCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry of the key.
+func (m *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elt, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	m.lru.MoveToFront(elt)
+
+	return elt.Value.(*memoryCacheItem).entry, true
+}
+
+// Set stores the entry of the key.
+// An entry larger than the maximum size is not stored.
+func (m *MemoryCache) Set(key string, entry *CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(key)
+
+	size := int64(len(entry.Data))
+	if size > m.maxSize {
+		return
+	}
+
+	m.entries[key] = m.lru.PushFront(&memoryCacheItem{key: key, entry: entry})
+	m.size += size
+
+	for m.size > m.maxSize {
+		m.remove(m.lru.Back().Value.(*memoryCacheItem).key)
+	}
+}
+
+func (m *MemoryCache) remove(key string) {
+	elt, ok := m.entries[key]
+	if !ok {
+		return
+	}
+
+	m.lru.Remove(elt)
+	delete(m.entries, key)
+
+	m.size -= int64(len(elt.Value.(*memoryCacheItem).entry.Data))
+}
+
+// DiskCache is a Cache stored inside a directory.
+// The entries are never evicted.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache Creates a new DiskCache.
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{dir: dir}
+}
+
+// diskCacheHeader is the first line of a cache file (followed by the data).
+type diskCacheHeader struct {
+	Key          string
+	ETag         string    `json:",omitempty"`
+	LastModified string    `json:",omitempty"`
+	Expires      time.Time `json:",omitzero"`
+}
+
+// Get returns the entry of the key.
+func (d *DiskCache) Get(key string) (*CacheEntry, bool) {
+	file, err := os.Open(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	defer func() { _ = file.Close() }()
+
+	r := bufio.NewReader(file)
+
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, false
+	}
+
+	var header diskCacheHeader
+
+	err = json.Unmarshal(line, &header)
+	if err != nil || header.Key != key {
+		return nil, false
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false
+	}
+
+	return &CacheEntry{
+		Data:         data,
+		ETag:         header.ETag,
+		LastModified: header.LastModified,
+		Expires:      header.Expires,
+	}, true
+}
+
+// Set stores the entry of the key.
+// The errors are ignored: the entry is not stored.
+func (d *DiskCache) Set(key string, entry *CacheEntry) {
+	header, err := json.Marshal(diskCacheHeader{
+		Key:          key,
+		ETag:         entry.ETag,
+		LastModified: entry.LastModified,
+		Expires:      entry.Expires,
+	})
+	if err != nil {
+		return
+	}
+
+	header = append(header, '\n')
+
+	_ = fsutil.WriteFile(d.path(key), io.MultiReader(bytes.NewReader(header), bytes.NewReader(entry.Data)))
+}
+
+// path returns the file of a key: <dir>/<2 first chars of the hash>/<hash>.
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	h := hex.EncodeToString(sum[:])
+
+	return filepath.Join(d.dir, h[:2], h)
+}
@@ -0,0 +1,253 @@
+package goproxy
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheCounters struct {
+	infoCalls   atomic.Int64
+	listCalls   atomic.Int64
+	zipCalls    atomic.Int64
+	notModified atomic.Int64
+}
+
+// handler serves the module github.com/ldez/grignotin and counts the requests.
+func (counters *cacheCounters) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.info",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			counters.infoCalls.Add(1)
+			_, _ = rw.Write([]byte(`{"Version":"v0.1.0","Time":"2020-01-01T00:00:00Z"}`))
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.zip",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			counters.zipCalls.Add(1)
+			_, _ = rw.Write([]byte("zip"))
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+		func(rw http.ResponseWriter, req *http.Request) {
+			counters.listCalls.Add(1)
+
+			if req.Header.Get("If-None-Match") == `"v1"` {
+				counters.notModified.Add(1)
+				rw.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+
+			rw.Header().Set("ETag", `"v1"`)
+			_, _ = rw.Write([]byte("v0.1.0\n"))
+		})
+
+	return mux
+}
+
+func TestClient_cache_immutable(t *testing.T) {
+	counters := &cacheCounters{}
+
+	client := setupServer(t, counters.handler())
+	client.Cache = NewMemoryCache(1 << 20)
+	client.CacheTTL = time.Hour
+
+	for range 3 {
+		info, err := client.GetInfo("github.com/ldez/grignotin", "v0.1.0")
+		require.NoError(t, err)
+
+		assert.Equal(t, "v0.1.0", info.Version)
+	}
+
+	assert.Equal(t, int64(1), counters.infoCalls.Load())
+}
+
+func TestClient_cache_zip(t *testing.T) {
+	counters := &cacheCounters{}
+
+	client := setupServer(t, counters.handler())
+	client.Cache = NewMemoryCache(1 << 20)
+	client.CacheTTL = time.Hour
+
+	for range 2 {
+		body, err := client.DownloadSources("github.com/ldez/grignotin", "v0.1.0")
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(body)
+		require.NoError(t, err)
+
+		require.NoError(t, body.Close())
+
+		assert.Equal(t, "zip", string(content))
+	}
+
+	// The archives are streamed: they are not cached.
+	assert.Equal(t, int64(2), counters.zipCalls.Load())
+
+	_, ok := client.Cache.Get(client.proxies[0].url.JoinPath("github.com/ldez/grignotin/@v/v0.1.0.zip").String())
+	assert.False(t, ok)
+}
+
+func TestClient_cache_fresh(t *testing.T) {
+	counters := &cacheCounters{}
+
+	client := setupServer(t, counters.handler())
+	client.Cache = NewMemoryCache(1 << 20)
+	client.CacheTTL = time.Hour
+
+	for range 3 {
+		versions, err := client.GetVersions("github.com/ldez/grignotin")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"v0.1.0"}, versions)
+	}
+
+	assert.Equal(t, int64(1), counters.listCalls.Load())
+}
+
+func TestClient_cache_revalidation(t *testing.T) {
+	counters := &cacheCounters{}
+
+	client := setupServer(t, counters.handler())
+	client.Cache = NewDiskCache(t.TempDir())
+	client.CacheTTL = time.Nanosecond
+
+	for range 3 {
+		versions, err := client.GetVersions("github.com/ldez/grignotin")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"v0.1.0"}, versions)
+	}
+
+	assert.Equal(t, int64(3), counters.listCalls.Load())
+	assert.Equal(t, int64(2), counters.notModified.Load())
+}
+
+func TestClient_cache_error(t *testing.T) {
+	counters := &cacheCounters{}
+
+	client := setupServer(t, counters.handler())
+	client.Cache = NewMemoryCache(1 << 20)
+	client.CacheTTL = time.Hour
+
+	_, err := client.GetInfo("github.com/ldez/grignotin", "v0.2.0")
+	require.Error(t, err)
+
+	_, ok := client.Cache.Get(client.proxies[0].url.JoinPath("github.com/ldez/grignotin/@v/v0.2.0.info").String())
+	assert.False(t, ok)
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(10)
+
+	cache.Set("a", &CacheEntry{Data: []byte("aaaa")})
+	cache.Set("b", &CacheEntry{Data: []byte("bbbb")})
+
+	// "a" is now the most recently used entry.
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+
+	cache.Set("c", &CacheEntry{Data: []byte("cccc")})
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+
+	entry, ok := cache.Get("a")
+	require.True(t, ok)
+
+	assert.Equal(t, []byte("aaaa"), entry.Data)
+
+	// Too large.
+	cache.Set("d", &CacheEntry{Data: []byte("ddddddddddd")})
+
+	_, ok = cache.Get("d")
+	assert.False(t, ok)
+
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+}
+
+func TestDiskCache(t *testing.T) {
+	cache := NewDiskCache(t.TempDir())
+
+	_, ok := cache.Get("https://proxy.golang.org/github.com/ldez/grignotin/@v/list")
+	require.False(t, ok)
+
+	expected := &CacheEntry{
+		Data:         []byte("v0.1.0\nv0.2.0\n"),
+		ETag:         `"abc"`,
+		LastModified: "Wed, 01 Jan 2020 00:00:00 GMT",
+		Expires:      time.Date(2020, time.January, 1, 0, 1, 0, 0, time.UTC),
+	}
+
+	cache.Set("https://proxy.golang.org/github.com/ldez/grignotin/@v/list", expected)
+
+	entry, ok := cache.Get("https://proxy.golang.org/github.com/ldez/grignotin/@v/list")
+	require.True(t, ok)
+
+	assert.Equal(t, expected, entry)
+}
+
+func Test_isImmutable(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		endpoint string
+		expected bool
+	}{
+		{
+			desc:     "info",
+			endpoint: "https://proxy.golang.org/github.com/ldez/grignotin/@v/v0.1.0.info",
+			expected: true,
+		},
+		{
+			desc:     "zip of a pseudo-version",
+			endpoint: "https://proxy.golang.org/github.com/ldez/grignotin/@v/v0.0.0-20200101000000-abcdefabcdef.zip",
+			expected: true,
+		},
+		{
+			desc:     "escaped version",
+			endpoint: "https://proxy.golang.org/github.com/ldez/grignotin/@v/v1.0.0-!r!c.1.mod",
+			expected: true,
+		},
+		{
+			desc:     "incompatible version",
+			endpoint: "https://proxy.golang.org/github.com/ldez/grignotin/@v/v2.0.0+incompatible.mod",
+			expected: true,
+		},
+		{
+			desc:     "list",
+			endpoint: "https://proxy.golang.org/github.com/ldez/grignotin/@v/list",
+		},
+		{
+			desc:     "latest",
+			endpoint: "https://proxy.golang.org/github.com/ldez/grignotin/@latest",
+		},
+		{
+			desc:     "query",
+			endpoint: "https://proxy.golang.org/github.com/ldez/grignotin/@v/master.info",
+		},
+		{
+			desc:     "non-canonical version",
+			endpoint: "https://proxy.golang.org/github.com/ldez/grignotin/@v/v1.info",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(test.endpoint)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, isImmutable(u))
+		})
+	}
+}
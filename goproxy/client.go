@@ -8,6 +8,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,44 +17,200 @@ import (
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
-const defaultProxyURL = "https://proxy.golang.org"
-
 // VersionInfo is the representation of a version.
 type VersionInfo struct {
-	Name    string
-	Short   string
+	// Name is the complete ID of the revision in the repository (e.g. the commit hash).
+	// If the response does not contain it, it is the commit hash of the Origin,
+	// or the revision of a pseudo-version.
+	Name string
+	// Short is the shortened ID of the revision (e.g. the 12 first characters of the commit hash).
+	Short string
+
 	Version string
 	Time    time.Time
+
+	// Origin describes the provenance of the version.
+	// It is nil if the proxy does not provide it.
+	Origin *Origin `json:",omitempty"`
+}
+
+// UnmarshalJSON implements [json.Unmarshaler].
+// It derives Name and Short when they are not in the data.
+func (v *VersionInfo) UnmarshalJSON(data []byte) error {
+	type versionInfo VersionInfo
+
+	err := json.Unmarshal(data, (*versionInfo)(v))
+	if err != nil {
+		return err
+	}
+
+	if v.Name == "" {
+		v.Name = v.revision()
+	}
+
+	if v.Short == "" {
+		v.Short = shortRevision(v.Name)
+	}
+
+	return nil
+}
+
+// MarshalInfo returns the content of the .info file of the version, like the proxies and the module cache:
+// the Version, the Time, and the Origin (Name and Short are derived from them).
+func (v *VersionInfo) MarshalInfo() ([]byte, error) {
+	return json.Marshal(struct {
+		Version string
+		Time    time.Time
+		Origin  *Origin `json:",omitempty"`
+	}{
+		Version: v.Version,
+		Time:    v.Time,
+		Origin:  v.Origin,
+	})
+}
+
+// revision returns the commit hash of the Origin, or the revision of a pseudo-version.
+func (v *VersionInfo) revision() string {
+	if v.Origin != nil && v.Origin.Hash != "" {
+		return v.Origin.Hash
+	}
+
+	if module.IsPseudoVersion(v.Version) {
+		rev, err := module.PseudoVersionRev(v.Version)
+		if err == nil {
+			return rev
+		}
+	}
+
+	return ""
+}
+
+// shortRevision returns the 12 first characters of a revision (like the revision of a pseudo-version).
+func shortRevision(rev string) string {
+	const size = 12
+
+	if len(rev) > size {
+		return rev[:size]
+	}
+
+	return rev
+}
+
+// Origin describes the provenance of a version, as provided by the go command and the proxies.
+// https://go.dev/ref/mod#goproxy-protocol
+type Origin struct {
+	// VCS is the version control system (e.g. "git").
+	VCS string `json:",omitempty"`
+	// URL is the URL of the repository.
+	URL string `json:",omitempty"`
+	// Subdir is the directory of the module inside the repository.
+	Subdir string `json:",omitempty"`
+
+	// Hash is the commit hash of the version.
+	Hash string `json:",omitempty"`
+
+	// TagPrefix is the prefix of the tags of the module (e.g. "sub/" for a module in a subdirectory).
+	TagPrefix string `json:",omitempty"`
+	// TagSum is a hash of the tags matching TagPrefix.
+	TagSum string `json:",omitempty"`
+
+	// Ref is the reference (e.g. "refs/heads/main") resolved by a query.
+	Ref string `json:",omitempty"`
+
+	// RepoSum is a hash of the whole repository, used when the version is not found.
+	RepoSum string `json:",omitempty"`
 }
 
 // Client is the go modules proxy client.
 type Client struct {
-	proxyURL   *url.URL
+	proxies []proxySpec
+
 	HTTPClient *http.Client
+
+	// UserAgent is the value of the User-Agent header of the HTTP requests.
+	UserAgent string
+
+	// Header contains the headers added to the HTTP requests (e.g. to identify the traffic).
+	Header http.Header
+
+	// Timeouts are the timeouts of the HTTP requests by endpoint,
+	// including the retries and the reading of the response body.
+	// The requests to an endpoint without timeout are only limited by HTTPClient.
+	Timeouts map[Endpoint]time.Duration
+
+	// MaxResponseSize is the maximum size (in bytes) of a response body.
+	// Zero means no limit.
+	MaxResponseSize int64
+
+	// Direct is called when the "direct" element of the proxy list is reached.
+	// If nil, the lookup fails with [ErrDirectUnavailable].
+	Direct DirectFunc
+
+	// NoProxy is a comma-separated list of glob patterns (GONOPROXY) of module path prefixes
+	// that are always fetched with Direct instead of the proxy list.
+	NoProxy string
+
+	// NoSumDB is a comma-separated list of glob patterns (GONOSUMDB) of module path prefixes
+	// that must not be checked against the checksum database.
+	NoSumDB string
+
+	// Cache stores the responses of the HTTP proxies.
+	// The archives (.zip) are not cached: they are streamed.
+	// If nil, nothing is cached.
+	Cache Cache
+
+	// CacheTTL is the time to live of the mutable resources (list and latest version) inside the Cache.
+	// The default value is [DefaultCacheTTL].
+	CacheTTL time.Duration
+
+	// Retry configures the retries of the failed HTTP requests.
+	// The zero value disables the retries.
+	Retry RetryPolicy
+
+	// RateLimiter limits the number of HTTP requests.
+	// If nil, the requests are not limited.
+	RateLimiter *RateLimiter
+
+	// BatchConcurrency is the maximum number of concurrent requests of a batch (e.g. [Client.GetInfoBatch]).
+	// The default value is [DefaultBatchConcurrency].
+	BatchConcurrency int
+
+	// err is the error of an invalid configuration (see [NewClient]).
+	err error
+
+	// infoFlights and modFlights deduplicate the in-flight requests of the batches.
+	infoFlights flightGroup[*VersionInfo]
+	modFlights  flightGroup[[]byte]
 }
 
 // NewClient creates a new Client.
+// An empty proxyURL is replaced by "https://proxy.golang.org".
+//
+// If the proxy URL is invalid, all the requests of the client fail with the parsing error.
+// Use [New] and [WithProxyURL] to get the error when creating the client.
 func NewClient(proxyURL string) *Client {
-	client := &Client{
-		HTTPClient: &http.Client{Timeout: 10 * time.Second},
-	}
-
-	client.proxyURL, _ = url.Parse(defaultProxyURL)
-	if proxyURL != "" {
-		var err error
-
-		client.proxyURL, err = url.Parse(proxyURL)
-		if err != nil {
-			// Use a panic to be non-breaking, but the [NewClient] signature must be changed.
-			panic(err)
-		}
+	client, err := New(WithProxyURL(proxyURL))
+	if err != nil {
+		client = newClient()
+		client.err = err
 	}
 
 	return client
 }
 
+// NewClientFromProxyList creates a new Client from a GOPROXY value
+// (e.g. "https://corp.example,https://proxy.golang.org,direct").
+// https://go.dev/ref/mod#goproxy-protocol
+//
+// An empty value is replaced by the default value of the go command: "https://proxy.golang.org,direct".
+// A file:// URL refers to a local directory in the proxy layout (e.g. "file:///home/user/go/pkg/mod/cache/download").
+func NewClientFromProxyList(goproxy string) (*Client, error) {
+	return New(WithProxyList(goproxy))
+}
+
 // GetSources gets the contents of the archive file.
 func (c *Client) GetSources(moduleName, version string) ([]byte, error) {
 	return c.GetSourcesWithContext(context.Background(), moduleName, version)
@@ -61,25 +218,19 @@ func (c *Client) GetSources(moduleName, version string) ([]byte, error) {
 
 // GetSourcesWithContext gets the contents of the archive file.
 func (c *Client) GetSourcesWithContext(ctx context.Context, moduleName, version string) ([]byte, error) {
-	endpoint := c.proxyURL.JoinPath(mustEscapePath(moduleName), "@v", version+".zip")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	endpoint, err := versionEndpoint(moduleName, version, ".zip")
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	body, err := c.fetch(ctx, moduleName, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() { _ = resp.Body.Close() }()
+	defer func() { _ = body.Close() }()
 
-	if resp.StatusCode/100 != 2 {
-		return nil, handleError(resp)
-	}
-
-	raw, err := io.ReadAll(resp.Body)
+	raw, err := io.ReadAll(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -96,23 +247,12 @@ func (c *Client) DownloadSources(moduleName, version string) (io.ReadCloser, err
 // DownloadSourcesWithContext returns an io.ReadCloser that reads the contents of the archive file.
 // It is the caller's responsibility to close the ReadCloser.
 func (c *Client) DownloadSourcesWithContext(ctx context.Context, moduleName, version string) (io.ReadCloser, error) {
-	endpoint := c.proxyURL.JoinPath(mustEscapePath(moduleName), "@v", version+".zip")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	endpoint, err := versionEndpoint(moduleName, version, ".zip")
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode/100 != 2 {
-		return nil, handleError(resp)
-	}
-
-	return resp.Body, nil
+	return c.fetch(ctx, moduleName, endpoint)
 }
 
 // GetModFile gets go.mod file.
@@ -122,30 +262,34 @@ func (c *Client) GetModFile(moduleName, version string) (*modfile.File, error) {
 
 // GetModFileWithContext gets go.mod file.
 func (c *Client) GetModFileWithContext(ctx context.Context, moduleName, version string) (*modfile.File, error) {
-	endpoint := c.proxyURL.JoinPath(mustEscapePath(moduleName), "@v", version+".mod")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	all, err := c.GetRawModFileWithContext(ctx, moduleName, version)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
+	return modfile.Parse("go.mod", all, nil)
+}
 
-	defer func() { _ = resp.Body.Close() }()
+// GetRawModFile gets the content of the go.mod file.
+func (c *Client) GetRawModFile(moduleName, version string) ([]byte, error) {
+	return c.GetRawModFileWithContext(context.Background(), moduleName, version)
+}
 
-	if resp.StatusCode/100 != 2 {
-		return nil, handleError(resp)
+// GetRawModFileWithContext gets the content of the go.mod file.
+func (c *Client) GetRawModFileWithContext(ctx context.Context, moduleName, version string) ([]byte, error) {
+	endpoint, err := versionEndpoint(moduleName, version, ".mod")
+	if err != nil {
+		return nil, err
 	}
 
-	all, err := io.ReadAll(resp.Body)
+	body, err := c.fetch(ctx, moduleName, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	return modfile.Parse("go.mod", all, nil)
+	defer func() { _ = body.Close() }()
+
+	return io.ReadAll(body)
 }
 
 // GetVersions gets all available module versions.
@@ -159,32 +303,25 @@ func (c *Client) GetVersions(moduleName string) ([]string, error) {
 //
 //	<proxy URL>/<module name>/@v/list
 func (c *Client) GetVersionsWithContext(ctx context.Context, moduleName string) ([]string, error) {
-	endpoint := c.proxyURL.JoinPath(mustEscapePath(moduleName), "@v", "list")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := c.HTTPClient.Do(req)
+	body, err := c.fetch(ctx, moduleName, "@v/list")
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode/100 != 2 {
-		return nil, handleError(resp)
-	}
+	defer func() { _ = body.Close() }()
 
 	var versions []string
 
-	scanner := bufio.NewScanner(resp.Body)
+	scanner := bufio.NewScanner(body)
 	for scanner.Scan() {
 		line := scanner.Text()
 		versions = append(versions, line)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
 	return versions, nil
 }
 
@@ -199,7 +336,12 @@ func (c *Client) GetInfo(moduleName, version string) (*VersionInfo, error) {
 //
 //	<proxy URL>/<module name>/@v/<version>.info
 func (c *Client) GetInfoWithContext(ctx context.Context, moduleName, version string) (*VersionInfo, error) {
-	return c.getInfo(ctx, c.proxyURL.JoinPath(mustEscapePath(moduleName), "@v", version+".info"))
+	endpoint, err := versionEndpoint(moduleName, version, ".info")
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getInfo(ctx, moduleName, endpoint)
 }
 
 // GetLatest gets information about the latest module version.
@@ -213,50 +355,238 @@ func (c *Client) GetLatest(moduleName string) (*VersionInfo, error) {
 //
 //	<proxy URL>/<module name>/@latest
 func (c *Client) GetLatestWithContext(ctx context.Context, moduleName string) (*VersionInfo, error) {
-	return c.getInfo(ctx, c.proxyURL.JoinPath(mustEscapePath(moduleName), "@latest"))
+	return c.getInfo(ctx, moduleName, "@latest")
 }
 
-func (c *Client) getInfo(ctx context.Context, endpoint *url.URL) (*VersionInfo, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+func (c *Client) getInfo(ctx context.Context, moduleName, endpoint string) (*VersionInfo, error) {
+	body, err := c.fetch(ctx, moduleName, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	defer func() { _ = body.Close() }()
+
+	info := VersionInfo{}
+	//nolint:musttag // data from Go proxy.
+	err = json.NewDecoder(body).Decode(&info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// fetch walks the proxy list to get a resource of the module proxy protocol.
+// The endpoint is relative to the module (e.g. "@v/list").
+// It is the caller's responsibility to close the ReadCloser.
+func (c *Client) fetch(ctx context.Context, moduleName, endpoint string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+
+	err := c.walkProxies(moduleName, func(proxy proxySpec) error {
+		var err error
+
+		body, err = c.fetchFrom(ctx, proxy, moduleName, endpoint)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// walkProxies calls try with the elements of the proxy list until success, following the GOPROXY semantics.
+// The modules matching NoProxy only use the "direct" element.
+func (c *Client) walkProxies(moduleName string, try func(proxy proxySpec) error) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	err := module.CheckPath(moduleName)
+	if err != nil {
+		return err
+	}
+
+	if module.MatchPrefixPatterns(c.NoProxy, moduleName) {
+		if c.Direct == nil {
+			return fmt.Errorf("module lookup disabled by GONOPROXY=%s: %w", c.NoProxy, ErrDirectUnavailable)
+		}
+
+		return try(proxySpec{name: ProxyDirect})
+	}
+
+	var best rankedError
+
+	for _, proxy := range c.proxies {
+		err = try(proxy)
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, ErrDirectUnavailable) {
+			if best.err == nil {
+				best.err = err
+			}
+
+			break
+		}
+
+		notExist := isNotExist(err)
+
+		best.add(proxy, err, notExist)
+
+		if !proxy.fallBackOnError && !notExist {
+			break
+		}
+	}
+
+	return best.err
+}
+
+// Same error ranking as the go command:
+// an error from "direct" is more relevant than an error from a proxy,
+// and an error from a proxy is more relevant than a "not found" error.
+const (
+	notExistRank = iota
+	proxyRank
+	directRank
+)
+
+// rankedError is the most relevant error of the proxy list.
+type rankedError struct {
+	err  error
+	rank int
+}
+
+// add keeps the error if it is more relevant than the current error.
+func (r *rankedError) add(proxy proxySpec, err error, notExist bool) {
+	switch {
+	case proxy.name == ProxyDirect:
+		r.err = err
+		r.rank = directRank
+
+	case r.rank <= proxyRank && !notExist:
+		r.err = err
+		r.rank = proxyRank
+
+	case r.rank == notExistRank:
+		r.err = err
+	}
+}
+
+func (c *Client) fetchFrom(ctx context.Context, proxy proxySpec, moduleName, endpoint string) (io.ReadCloser, error) {
+	switch proxy.name {
+	case ProxyOff:
+		return nil, ErrProxyOff
+
+	case ProxyDirect:
+		if c.Direct == nil {
+			return nil, ErrDirectUnavailable
+		}
+
+		return c.Direct(ctx, moduleName, endpoint)
+
+	default:
+		if proxy.url.Scheme == "file" {
+			return fetchFile(proxy.url, moduleName, endpoint)
+		}
+
+		u, err := escapedURL(proxy.url, moduleName, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		var body io.ReadCloser
+
+		// The archives are not read into memory to be cached.
+		if c.Cache != nil && endpointOf(u) != EndpointZip {
+			body, err = c.fetchHTTPCached(ctx, u)
+		} else {
+			body, err = c.fetchHTTP(ctx, u)
+		}
+
+		return body, withModule(err, moduleName, endpoint)
+	}
+}
+
+func (c *Client) fetchHTTP(ctx context.Context, endpoint *url.URL) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() { _ = resp.Body.Close() }()
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
 
 	if resp.StatusCode/100 != 2 {
+		defer func() { _ = resp.Body.Close() }()
+
 		return nil, handleError(resp)
 	}
 
-	info := VersionInfo{}
-	//nolint:musttag // data from Go proxy.
-	err = json.NewDecoder(resp.Body).Decode(&info)
+	return resp.Body, nil
+}
+
+// UseSumDB reports whether the module must be checked against the checksum database (GONOSUMDB).
+func (c *Client) UseSumDB(moduleName string) bool {
+	return !module.MatchPrefixPatterns(c.NoSumDB, moduleName)
+}
+
+// escapedURL returns the URL of a resource of the module.
+// The endpoint is relative to the module and already escaped (e.g. "@v/list").
+func escapedURL(base *url.URL, moduleName, endpoint string) (*url.URL, error) {
+	escPath, err := module.EscapePath(moduleName)
 	if err != nil {
 		return nil, err
 	}
 
-	return &info, nil
+	return base.JoinPath(escPath, endpoint), nil
 }
 
-func mustEscapePath(path string) string {
-	escapePath, err := module.EscapePath(path)
+// versionEndpoint checks the module path and the version,
+// and returns the escaped endpoint of a version resource (e.g. "@v/v1.0.0-!r!c1.info").
+func versionEndpoint(moduleName, version, ext string) (string, error) {
+	err := checkModuleVersion(moduleName, version)
+	if err != nil {
+		return "", err
+	}
+
+	escVersion, err := module.EscapeVersion(version)
 	if err != nil {
-		panic(err)
+		return "", fmt.Errorf("%s@%s: %w: %w", moduleName, version, ErrInvalidVersion, err)
 	}
 
-	return escapePath
+	return "@v/" + escVersion + ext, nil
 }
 
-func handleError(resp *http.Response) error {
-	all, _ := io.ReadAll(resp.Body)
+// checkModuleVersion checks the module path,
+// and the consistency between the major version suffix of the path and a semantic version.
+func checkModuleVersion(moduleName, version string) error {
+	err := module.CheckPath(moduleName)
+	if err != nil {
+		return err
+	}
+
+	if version == "" {
+		return fmt.Errorf("%s: %w: empty version", moduleName, ErrInvalidVersion)
+	}
+
+	if !semver.IsValid(version) {
+		return nil
+	}
+
+	_, pathMajor, ok := module.SplitPathVersion(moduleName)
+	if !ok {
+		return fmt.Errorf("%s: invalid major version suffix", moduleName)
+	}
 
-	return &APIError{
-		StatusCode: resp.StatusCode,
-		Message:    fmt.Sprintf("%s: %s", resp.Status, string(all)),
+	err = module.CheckPathMajor(version, pathMajor)
+	if err != nil {
+		return fmt.Errorf("%s@%s: %w: %w", moduleName, version, ErrInvalidVersion, err)
 	}
+
+	return nil
 }
@@ -1,10 +1,14 @@
 package goproxy
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -116,6 +120,8 @@ func TestClient_GetInfo(t *testing.T) {
 	require.NoError(t, err)
 
 	expected := &VersionInfo{
+		Name:    "a8b993ba6abd",
+		Short:   "a8b993ba6abd",
 		Version: "v0.0.0-20170406111628-a8b993ba6abd",
 		Time:    time.Date(2017, time.April, 6, 11, 16, 28, 0, time.UTC),
 	}
@@ -178,13 +184,118 @@ func TestClient_GetLatest(t *testing.T) {
 	require.NoError(t, err)
 
 	expected := &VersionInfo{
+		Name:    "818c5a80406779e3ce2860365fc289de6d133b00",
+		Short:   "818c5a804067",
 		Version: "v0.0.0-20241112194109-818c5a804067",
 		Time:    time.Date(2024, time.November, 12, 19, 41, 9, 0, time.UTC),
+		Origin: &Origin{
+			VCS:  "git",
+			URL:  "https://go.googlesource.com/lint",
+			Hash: "818c5a80406779e3ce2860365fc289de6d133b00",
+		},
 	}
 
 	assert.Equal(t, expected, info)
 }
 
+func TestVersionInfo_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		data     string
+		expected *VersionInfo
+	}{
+		{
+			desc: "tag with origin",
+			data: `{"Version":"v0.1.0","Time":"2024-11-12T19:41:09Z","Origin":{"VCS":"git","URL":"https://github.com/ldez/grignotin","Subdir":"sub","Hash":"818c5a80406779e3ce2860365fc289de6d133b00","Ref":"refs/tags/sub/v0.1.0","TagPrefix":"sub/","TagSum":"t1:abc","RepoSum":"r1:def"}}`,
+			expected: &VersionInfo{
+				Name:    "818c5a80406779e3ce2860365fc289de6d133b00",
+				Short:   "818c5a804067",
+				Version: "v0.1.0",
+				Time:    time.Date(2024, time.November, 12, 19, 41, 9, 0, time.UTC),
+				Origin: &Origin{
+					VCS:       "git",
+					URL:       "https://github.com/ldez/grignotin",
+					Subdir:    "sub",
+					Hash:      "818c5a80406779e3ce2860365fc289de6d133b00",
+					TagPrefix: "sub/",
+					TagSum:    "t1:abc",
+					Ref:       "refs/tags/sub/v0.1.0",
+					RepoSum:   "r1:def",
+				},
+			},
+		},
+		{
+			desc: "tag without origin",
+			data: `{"Version":"v0.1.0","Time":"2024-11-12T19:41:09Z"}`,
+			expected: &VersionInfo{
+				Version: "v0.1.0",
+				Time:    time.Date(2024, time.November, 12, 19, 41, 9, 0, time.UTC),
+			},
+		},
+		{
+			desc: "pseudo-version",
+			data: `{"Version":"v0.0.0-20241112194109-818c5a804067","Time":"2024-11-12T19:41:09Z"}`,
+			expected: &VersionInfo{
+				Name:    "818c5a804067",
+				Short:   "818c5a804067",
+				Version: "v0.0.0-20241112194109-818c5a804067",
+				Time:    time.Date(2024, time.November, 12, 19, 41, 9, 0, time.UTC),
+			},
+		},
+		{
+			desc: "name and short",
+			data: `{"Version":"v0.1.0","Name":"818c5a80406779e3ce2860365fc289de6d133b00","Short":"818c5a8","Time":"2024-11-12T19:41:09Z"}`,
+			expected: &VersionInfo{
+				Name:    "818c5a80406779e3ce2860365fc289de6d133b00",
+				Short:   "818c5a8",
+				Version: "v0.1.0",
+				Time:    time.Date(2024, time.November, 12, 19, 41, 9, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			info := &VersionInfo{}
+
+			err := json.Unmarshal([]byte(test.data), info)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, info)
+		})
+	}
+}
+
+func TestVersionInfo_MarshalInfo(t *testing.T) {
+	info := &VersionInfo{
+		Name:    "818c5a80406779e3ce2860365fc289de6d133b00",
+		Short:   "818c5a804067",
+		Version: "v0.1.0",
+		Time:    time.Date(2024, time.November, 12, 19, 41, 9, 0, time.UTC),
+		Origin: &Origin{
+			VCS:  "git",
+			URL:  "https://github.com/ldez/grignotin",
+			Hash: "818c5a80406779e3ce2860365fc289de6d133b00",
+		},
+	}
+
+	data, err := info.MarshalInfo()
+	require.NoError(t, err)
+
+	expected := `{"Version":"v0.1.0","Time":"2024-11-12T19:41:09Z","Origin":{"VCS":"git","URL":"https://github.com/ldez/grignotin","Hash":"818c5a80406779e3ce2860365fc289de6d133b00"}}`
+
+	assert.JSONEq(t, expected, string(data))
+
+	decoded := &VersionInfo{}
+
+	err = json.Unmarshal(data, decoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, info, decoded)
+}
+
 func TestClient_GetLatest_integration(t *testing.T) {
 	client := NewClient("")
 
@@ -193,3 +304,206 @@ func TestClient_GetLatest_integration(t *testing.T) {
 
 	fmt.Println(info)
 }
+
+func TestNewClientFromProxyList_fallback(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		separator  string
+		statusCode int
+		assertErr  require.ErrorAssertionFunc
+		expected   []string
+	}{
+		{
+			desc:       "comma: not found",
+			separator:  ",",
+			statusCode: http.StatusNotFound,
+			assertErr:  require.NoError,
+			expected:   []string{"v1.0.0"},
+		},
+		{
+			desc:       "comma: gone",
+			separator:  ",",
+			statusCode: http.StatusGone,
+			assertErr:  require.NoError,
+			expected:   []string{"v1.0.0"},
+		},
+		{
+			desc:       "comma: server error",
+			separator:  ",",
+			statusCode: http.StatusInternalServerError,
+			assertErr:  require.Error,
+		},
+		{
+			desc:       "pipe: server error",
+			separator:  "|",
+			statusCode: http.StatusInternalServerError,
+			assertErr:  require.NoError,
+			expected:   []string{"v1.0.0"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			first := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(test.statusCode)
+			}))
+			t.Cleanup(first.Close)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list", func(rw http.ResponseWriter, _ *http.Request) {
+				_, _ = fmt.Fprint(rw, "v1.0.0\n")
+			})
+
+			second := httptest.NewServer(mux)
+			t.Cleanup(second.Close)
+
+			client, err := NewClientFromProxyList(first.URL + test.separator + second.URL)
+			require.NoError(t, err)
+
+			versions, err := client.GetVersions("github.com/ldez/grignotin")
+			test.assertErr(t, err)
+
+			assert.Equal(t, test.expected, versions)
+		})
+	}
+}
+
+func TestNewClientFromProxyList_off(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	client, err := NewClientFromProxyList(server.URL + ",off")
+	require.NoError(t, err)
+
+	_, err = client.GetVersions("github.com/ldez/grignotin")
+	require.ErrorIs(t, err, ErrProxyOff)
+}
+
+func TestNewClientFromProxyList_direct(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	client, err := NewClientFromProxyList(server.URL + ",direct")
+	require.NoError(t, err)
+
+	_, err = client.GetVersions("github.com/ldez/grignotin")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	client.Direct = func(_ context.Context, moduleName, endpoint string) (io.ReadCloser, error) {
+		assert.Equal(t, "github.com/ldez/grignotin", moduleName)
+		assert.Equal(t, "@v/list", endpoint)
+
+		return io.NopCloser(strings.NewReader("v1.0.0\nv1.1.0\n")), nil
+	}
+
+	versions, err := client.GetVersions("github.com/ldez/grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0"}, versions)
+}
+
+func TestClient_GetInfo_escapedVersion(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0-!r!c1.info",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(rw, `{"Version":"v0.1.0-RC1","Time":"2017-04-06T11:16:28Z"}`)
+		})
+
+	client := NewClient(server.URL)
+
+	info, err := client.GetInfo("github.com/ldez/grignotin", "v0.1.0-RC1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.1.0-RC1", info.Version)
+}
+
+func TestClient_invalidInput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		t.Errorf("unexpected request: %s", req.URL)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+
+	testCases := []struct {
+		desc       string
+		moduleName string
+		version    string
+		assertErr  require.ErrorAssertionFunc
+	}{
+		{
+			desc:       "invalid module path",
+			moduleName: "github.com/ldez/grignotin/",
+			version:    "v0.1.0",
+			assertErr:  require.Error,
+		},
+		{
+			desc:       "empty version",
+			moduleName: "github.com/ldez/grignotin",
+			assertErr: func(t require.TestingT, err error, _ ...any) {
+				require.ErrorIs(t, err, ErrInvalidVersion)
+			},
+		},
+		{
+			desc:       "invalid characters",
+			moduleName: "github.com/ldez/grignotin",
+			version:    "v0.1.0 !",
+			assertErr: func(t require.TestingT, err error, _ ...any) {
+				require.ErrorIs(t, err, ErrInvalidVersion)
+			},
+		},
+		{
+			desc:       "major version mismatch",
+			moduleName: "github.com/ldez/grignotin",
+			version:    "v2.0.0",
+			assertErr: func(t require.TestingT, err error, _ ...any) {
+				require.ErrorIs(t, err, ErrInvalidVersion)
+			},
+		},
+		{
+			desc:       "major version suffix mismatch",
+			moduleName: "github.com/ldez/grignotin/v2",
+			version:    "v3.0.0",
+			assertErr: func(t require.TestingT, err error, _ ...any) {
+				require.ErrorIs(t, err, ErrInvalidVersion)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := client.GetInfo(test.moduleName, test.version)
+			test.assertErr(t, err)
+
+			_, err = client.GetRawModFile(test.moduleName, test.version)
+			test.assertErr(t, err)
+
+			_, err = client.GetSources(test.moduleName, test.version)
+			test.assertErr(t, err)
+
+			_, err = client.OpenSources(test.moduleName, test.version)
+			test.assertErr(t, err)
+		})
+	}
+}
+
+func TestClient_GetVersions_invalidModulePath(t *testing.T) {
+	client := NewClient("https://example.com")
+
+	_, err := client.GetVersions("../grignotin")
+	require.Error(t, err)
+}
+
+func setupServer(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(server.URL)
+}
@@ -0,0 +1,36 @@
+package goproxy
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/ldez/grignotin/goenv"
+)
+
+// NewClientFromEnv creates a new Client from the configuration of the go command (go env):
+// GOPROXY, GONOPROXY, GOPRIVATE, GONOSUMDB, and GOAUTH.
+//
+// The modules matching GONOPROXY (or GOPRIVATE if GONOPROXY is not set) are fetched with [Client.Direct].
+func NewClientFromEnv(ctx context.Context) (*Client, error) {
+	values, err := goenv.Get(ctx, goenv.GOPROXY, goenv.GONOPROXY, goenv.GOPRIVATE, goenv.GONOSUMDB, goenv.GOAUTH)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := NewClientFromProxyList(values[goenv.GOPROXY])
+	if err != nil {
+		return nil, err
+	}
+
+	client.NoProxy = cmp.Or(values[goenv.GONOPROXY], values[goenv.GOPRIVATE])
+	client.NoSumDB = cmp.Or(values[goenv.GONOSUMDB], values[goenv.GOPRIVATE])
+
+	transport, err := NewGoAuthTransport(values[goenv.GOAUTH])
+	if err != nil {
+		return nil, err
+	}
+
+	transport.Wrap(client.HTTPClient)
+
+	return client, nil
+}
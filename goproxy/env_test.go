@@ -0,0 +1,67 @@
+package goproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientFromEnv(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+		func(rw http.ResponseWriter, req *http.Request) {
+			user, pass, ok := req.BasicAuth()
+			if !ok || user != "user" || pass != "secret" {
+				rw.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			_, _ = fmt.Fprint(rw, "v1.0.0\n")
+		})
+
+	netrc := filepath.Join(t.TempDir(), ".netrc")
+	err := os.WriteFile(netrc, []byte("machine 127.0.0.1 login user password secret\n"), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("GOENV", "off")
+	t.Setenv("NETRC", netrc)
+	t.Setenv("GOAUTH", "netrc")
+	t.Setenv("GOPROXY", server.URL+",direct")
+	t.Setenv("GOPRIVATE", "example.com/private")
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GONOSUMDB", "")
+
+	client, err := NewClientFromEnv(t.Context())
+	require.NoError(t, err)
+
+	client.HTTPClient.Transport.(*GoAuthTransport).Transport = server.Client().Transport
+
+	client.Direct = func(_ context.Context, _, _ string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("v0.1.0\n")), nil
+	}
+
+	versions, err := client.GetVersions("github.com/ldez/grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.0.0"}, versions)
+
+	versions, err = client.GetVersions("example.com/private/foo")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.1.0"}, versions)
+
+	assert.True(t, client.UseSumDB("github.com/ldez/grignotin"))
+	assert.False(t, client.UseSumDB("example.com/private/foo"))
+}
@@ -1,13 +1,123 @@
 package goproxy
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize is the maximum size of the body read from an error response.
+const maxErrorBodySize = 4 << 10
+
+// Errors matched by [APIError] (with [errors.Is]).
+var (
+	// ErrNotFound matches a 404 response.
+	ErrNotFound = errors.New("not found")
+	// ErrGone matches a 410 response (e.g. a version removed from the proxy).
+	ErrGone = errors.New("gone")
+	// ErrUnauthorized matches a 401 or 403 response.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRateLimited matches a 429 response.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrInvalidVersion matches a 400 response, or a response explaining that the version is invalid
+	// (e.g. "not found: github.com/foo/bar@v1.0.0: invalid version: unknown revision v1.0.0").
+	ErrInvalidVersion = errors.New("invalid version")
+)
 
 // APIError represents an error from the GoProxy.
 type APIError struct {
 	StatusCode int
 	Message    string
+
+	// Reason and Detail are the parts of a message formatted like the messages of proxy.golang.org:
+	// "<reason>: <detail>" (e.g. "not found: module github.com/foo/bar: no matching versions for query "latest"").
+	// Reason is empty if the message does not have this format.
+	Reason string
+	Detail string
+
+	// Module is the module path, and Endpoint is the resource relative to the module (e.g. "@v/list").
+	Module   string
+	Endpoint string
+
+	// URL is the URL of the request.
+	URL string
 }
 
 func (a *APIError) Error() string {
-	return fmt.Sprintf("error: %d: %s", a.StatusCode, a.Message)
+	if a.Module == "" {
+		return fmt.Sprintf("error: %d: %s", a.StatusCode, a.Message)
+	}
+
+	return fmt.Sprintf("%s/%s: error: %d: %s", a.Module, a.Endpoint, a.StatusCode, a.Message)
+}
+
+// Is reports whether the error matches the target.
+// A 404 or 410 response matches [fs.ErrNotExist].
+func (a *APIError) Is(target error) bool {
+	switch target {
+	case fs.ErrNotExist:
+		return a.StatusCode == http.StatusNotFound || a.StatusCode == http.StatusGone
+
+	case ErrNotFound:
+		return a.StatusCode == http.StatusNotFound
+
+	case ErrGone:
+		return a.StatusCode == http.StatusGone
+
+	case ErrUnauthorized:
+		return a.StatusCode == http.StatusUnauthorized || a.StatusCode == http.StatusForbidden
+
+	case ErrRateLimited:
+		return a.StatusCode == http.StatusTooManyRequests
+
+	case ErrInvalidVersion:
+		return a.StatusCode == http.StatusBadRequest || strings.Contains(a.Detail, "invalid version")
+
+	default:
+		return false
+	}
+}
+
+func handleError(resp *http.Response) error {
+	all, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	body := strings.TrimSpace(string(all))
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    fmt.Sprintf("%s: %s", resp.Status, body),
+	}
+
+	apiErr.Reason, apiErr.Detail = parseErrorBody(body)
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		apiErr.URL = resp.Request.URL.Redacted()
+	}
+
+	return apiErr
+}
+
+// parseErrorBody splits a message formatted like the messages of proxy.golang.org ("<reason>: <detail>").
+func parseErrorBody(body string) (reason, detail string) {
+	for _, r := range []string{"not found", "gone", "bad request", "forbidden", "unauthorized", "too many requests"} {
+		if d, ok := strings.CutPrefix(body, r+": "); ok {
+			return r, d
+		}
+	}
+
+	return "", body
+}
+
+// withModule adds the module path and the endpoint to an APIError.
+func withModule(err error, moduleName, endpoint string) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Module == "" {
+		apiErr.Module = moduleName
+		apiErr.Endpoint = endpoint
+	}
+
+	return err
 }
@@ -0,0 +1,166 @@
+package goproxy
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		statusCode int
+		body       string
+		matches    []error
+		notMatches []error
+	}{
+		{
+			desc:       "not found",
+			statusCode: http.StatusNotFound,
+			body:       `not found: module github.com/ldez/grignotin: no matching versions for query "latest"`,
+			matches:    []error{fs.ErrNotExist, ErrNotFound},
+			notMatches: []error{ErrGone, ErrInvalidVersion, ErrUnauthorized, ErrRateLimited},
+		},
+		{
+			desc:       "unknown revision",
+			statusCode: http.StatusNotFound,
+			body:       "not found: github.com/ldez/grignotin@v9.0.0: invalid version: unknown revision v9.0.0",
+			matches:    []error{fs.ErrNotExist, ErrNotFound, ErrInvalidVersion},
+			notMatches: []error{ErrGone},
+		},
+		{
+			desc:       "gone",
+			statusCode: http.StatusGone,
+			body:       "not found: github.com/ldez/grignotin@v0.1.0: reading zip: not available",
+			matches:    []error{fs.ErrNotExist, ErrGone},
+			notMatches: []error{ErrNotFound},
+		},
+		{
+			desc:       "bad request",
+			statusCode: http.StatusBadRequest,
+			body:       "bad request: invalid escaped module path",
+			matches:    []error{ErrInvalidVersion},
+			notMatches: []error{fs.ErrNotExist},
+		},
+		{
+			desc:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			matches:    []error{ErrUnauthorized},
+			notMatches: []error{fs.ErrNotExist},
+		},
+		{
+			desc:       "forbidden",
+			statusCode: http.StatusForbidden,
+			matches:    []error{ErrUnauthorized},
+		},
+		{
+			desc:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			matches:    []error{ErrRateLimited},
+			notMatches: []error{fs.ErrNotExist},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := &APIError{StatusCode: test.statusCode}
+			err.Reason, err.Detail = parseErrorBody(test.body)
+
+			for _, target := range test.matches {
+				assert.ErrorIs(t, err, target)
+			}
+
+			for _, target := range test.notMatches {
+				assert.NotErrorIs(t, err, target)
+			}
+		})
+	}
+}
+
+func TestClient_GetInfo_apiError(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v1.9.0.info",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			http.Error(rw, "not found: github.com/ldez/grignotin@v1.9.0: invalid version: unknown revision v1.9.0", http.StatusNotFound)
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			http.Error(rw, strings.Repeat("a", 2*maxErrorBodySize), http.StatusInternalServerError)
+		})
+
+	client := NewClient(server.URL)
+
+	_, err := client.GetInfo("github.com/ldez/grignotin", "v1.9.0")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, err, ErrInvalidVersion)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+
+	expected := &APIError{
+		StatusCode: http.StatusNotFound,
+		Message:    "404 Not Found: not found: github.com/ldez/grignotin@v1.9.0: invalid version: unknown revision v1.9.0",
+		Reason:     "not found",
+		Detail:     "github.com/ldez/grignotin@v1.9.0: invalid version: unknown revision v1.9.0",
+		Module:     "github.com/ldez/grignotin",
+		Endpoint:   "@v/v1.9.0.info",
+		URL:        server.URL + "/github.com/ldez/grignotin/@v/v1.9.0.info",
+	}
+
+	assert.Equal(t, expected, apiErr)
+	assert.EqualError(t, err, "github.com/ldez/grignotin/@v/v1.9.0.info: error: 404: "+expected.Message)
+
+	_, err = client.GetVersions("github.com/ldez/grignotin")
+	require.ErrorAs(t, err, &apiErr)
+
+	assert.Len(t, apiErr.Detail, maxErrorBodySize)
+}
+
+func Test_parseErrorBody(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		body           string
+		expectedReason string
+		expectedDetail string
+	}{
+		{
+			desc:           "not found",
+			body:           "not found: module github.com/ldez/grignotin: no matching versions",
+			expectedReason: "not found",
+			expectedDetail: "module github.com/ldez/grignotin: no matching versions",
+		},
+		{
+			desc:           "bad request",
+			body:           "bad request: invalid escaped module path",
+			expectedReason: "bad request",
+			expectedDetail: "invalid escaped module path",
+		},
+		{
+			desc:           "unstructured",
+			body:           "<html>error</html>",
+			expectedDetail: "<html>error</html>",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			reason, detail := parseErrorBody(test.body)
+
+			assert.Equal(t, test.expectedReason, reason)
+			assert.Equal(t, test.expectedDetail, detail)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// ExtractOptions configures the extraction of a module zip.
+type ExtractOptions struct {
+	// SkipVendor omits the files inside vendor directories.
+	SkipVendor bool
+}
+
+// ExtractSources downloads the archive file and extracts it into the directory.
+// See [Client.ExtractSourcesWithContext].
+func (c *Client) ExtractSources(moduleName, version, dir string, opts ExtractOptions) (fs.FS, error) {
+	return c.ExtractSourcesWithContext(context.Background(), moduleName, version, dir, opts)
+}
+
+// ExtractSourcesWithContext downloads the archive file and extracts it into the directory.
+//
+// The archive is streamed into a temporary file (it is never entirely in memory),
+// and it is extracted with [modzip.Unzip]: it must satisfy the module zip restrictions (https://pkg.go.dev/golang.org/x/mod/zip):
+// size limits, no case-insensitive collisions, no invalid paths.
+// The "<module>@<version>/" prefix is removed, and the files are read-only.
+//
+// The directory must not exist or be empty.
+// The archive is extracted into a temporary directory renamed at the end:
+// nothing is left behind if the extraction fails.
+// Returns a file system rooted at the directory.
+func (c *Client) ExtractSourcesWithContext(ctx context.Context, moduleName, version, dir string, opts ExtractOptions) (fs.FS, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if len(files) > 0 {
+		return nil, fmt.Errorf("target directory %s exists and is not empty", dir)
+	}
+
+	file, err := c.downloadToTemp(ctx, moduleName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	err = extractZip(file.Name(), module.Version{Path: moduleName, Version: version}, dir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s@%s: extract: %w", moduleName, version, err)
+	}
+
+	return os.DirFS(dir), nil
+}
+
+// extractZip extracts the module zip into a temporary directory, then renames the temporary directory.
+func extractZip(zipFile string, m module.Version, dir string, opts ExtractOptions) error {
+	err := os.MkdirAll(filepath.Dir(dir), 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return err
+	}
+
+	err = unzip(zipFile, m, tmp, opts)
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+
+	// The directory is empty (or does not exist).
+	err = os.Remove(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+
+	err = os.Rename(tmp, dir)
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+
+	return nil
+}
+
+func unzip(zipFile string, m module.Version, dir string, opts ExtractOptions) error {
+	// Like the directories created by modzip.Unzip.
+	err := os.Chmod(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	err = modzip.Unzip(dir, m, zipFile)
+	if err != nil {
+		return err
+	}
+
+	if !opts.SkipVendor {
+		return nil
+	}
+
+	return removeVendored(dir)
+}
+
+// removeVendored removes the files of the vendored packages, and the directories left empty.
+func removeVendored(dir string) error {
+	var dirs []string
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			dirs = append(dirs, p)
+			return nil
+		}
+
+		name, errRel := filepath.Rel(dir, p)
+		if errRel != nil {
+			return errRel
+		}
+
+		if !isVendored(filepath.ToSlash(name)) {
+			return nil
+		}
+
+		return os.Remove(p)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The deepest directories first.
+	for _, d := range slices.Backward(dirs[1:]) {
+		if entries, _ := os.ReadDir(d); len(entries) == 0 {
+			err = os.Remove(d)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isVendored reports whether the file belongs to a vendored package (like modzip):
+// the files inside the subdirectories of a vendor directory, but not "vendor/modules.txt".
+func isVendored(name string) bool {
+	var i int
+
+	if strings.HasPrefix(name, "vendor/") {
+		i = len("vendor/")
+	} else if j := strings.Index(name, "/vendor/"); j >= 0 {
+		i = j + len("/vendor/")
+	} else {
+		return false
+	}
+
+	return strings.Contains(name[i:], "/")
+}
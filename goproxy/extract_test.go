@@ -0,0 +1,168 @@
+package goproxy
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ldez/grignotin/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zipHandler serves the archive of github.com/ldez/grignotin@v0.1.0.
+func zipHandler(archive []byte) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.zip",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = rw.Write(archive)
+		})
+
+	return mux
+}
+
+func TestClient_ExtractSources(t *testing.T) {
+	archive := testutil.CreateZip(t, map[string]string{
+		"github.com/ldez/grignotin@v0.1.0/go.mod":                  testModFile,
+		"github.com/ldez/grignotin@v0.1.0/foo/foo.go":              "package foo\n",
+		"github.com/ldez/grignotin@v0.1.0/vendor/modules.txt":      "",
+		"github.com/ldez/grignotin@v0.1.0/vendor/example.com/a.go": "package a\n",
+	})
+
+	client := setupServer(t, zipHandler(archive))
+
+	testCases := []struct {
+		desc     string
+		opts     ExtractOptions
+		expected []string
+	}{
+		{
+			desc:     "all files",
+			expected: []string{"foo/foo.go", "go.mod", "vendor/example.com/a.go", "vendor/modules.txt"},
+		},
+		{
+			desc:     "skip vendor",
+			opts:     ExtractOptions{SkipVendor: true},
+			expected: []string{"foo/foo.go", "go.mod", "vendor/modules.txt"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "extract")
+
+			fsys, err := client.ExtractSources("github.com/ldez/grignotin", "v0.1.0", dir, test.opts)
+			require.NoError(t, err)
+
+			var files []string
+
+			err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+
+				files = append(files, p)
+
+				return nil
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, files)
+
+			content, err := fs.ReadFile(fsys, "go.mod")
+			require.NoError(t, err)
+
+			assert.Equal(t, testModFile, string(content))
+		})
+	}
+}
+
+func TestClient_ExtractSources_invalid(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		files map[string]string
+	}{
+		{
+			desc: "wrong prefix",
+			files: map[string]string{
+				"github.com/ldez/other@v0.1.0/go.mod": testModFile,
+			},
+		},
+		{
+			desc: "path traversal",
+			files: map[string]string{
+				"github.com/ldez/grignotin@v0.1.0/../../evil.go": "package evil\n",
+			},
+		},
+		{
+			desc: "case-insensitive collision",
+			files: map[string]string{
+				"github.com/ldez/grignotin@v0.1.0/README.md": "",
+				"github.com/ldez/grignotin@v0.1.0/readme.md": "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := setupServer(t, zipHandler(testutil.CreateZip(t, test.files)))
+
+			parent := t.TempDir()
+
+			_, err := client.ExtractSources("github.com/ldez/grignotin", "v0.1.0", filepath.Join(parent, "extract"), ExtractOptions{})
+			require.Error(t, err)
+
+			// Nothing is left behind.
+			entries, err := os.ReadDir(parent)
+			require.NoError(t, err)
+
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestClient_ExtractSources_not_empty(t *testing.T) {
+	archive := testutil.CreateZip(t, map[string]string{
+		"github.com/ldez/grignotin@v0.1.0/go.mod": testModFile,
+	})
+
+	client := setupServer(t, zipHandler(archive))
+
+	dir := filepath.Join("..", "goproxy")
+
+	_, err := client.ExtractSources("github.com/ldez/grignotin", "v0.1.0", dir, ExtractOptions{})
+	require.Error(t, err)
+}
+
+func TestClient_ExtractSources_not_directory(t *testing.T) {
+	client := setupServer(t, http.NotFoundHandler())
+
+	dir := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(dir, nil, 0o600))
+
+	_, err := client.ExtractSources("github.com/ldez/grignotin", "v0.1.0", dir, ExtractOptions{})
+	require.Error(t, err)
+
+	assert.NotErrorIs(t, err, fs.ErrNotExist)
+}
+
+func Test_isVendored(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "vendor/modules.txt"},
+		{name: "vendor/example.com/a.go", expected: true},
+		{name: "foo/vendor/example.com/a.go", expected: true},
+		{name: "foo/vendor/vendor.go"},
+		{name: "vendor.go"},
+		{name: "foo/foo.go"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, isVendored(test.name), test.name)
+	}
+}
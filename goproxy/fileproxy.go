@@ -0,0 +1,93 @@
+package goproxy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ldez/grignotin/internal/modver"
+	"github.com/ldez/grignotin/internal/proxylist"
+	"golang.org/x/mod/module"
+)
+
+// fetchFile gets a resource from a file:// proxy:
+// a directory in the proxy layout (e.g. "$GOMODCACHE/cache/download").
+//
+// When the "@latest" file does not exist (a module cache does not contain it),
+// the information of the latest version of the "@v/list" file is used.
+func fetchFile(u *url.URL, moduleName, endpoint string) (io.ReadCloser, error) {
+	dir, err := proxylist.FileURLToPath(u)
+	if err != nil {
+		return nil, err
+	}
+
+	escPath, err := module.EscapePath(moduleName)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(dir, filepath.FromSlash(escPath))
+
+	file, err := os.Open(filepath.Join(root, filepath.FromSlash(endpoint)))
+	if err == nil {
+		return file, nil
+	}
+
+	if endpoint != "@latest" || !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	version, err := latestFromList(filepath.Join(root, "@v", "list"))
+	if err != nil {
+		return nil, err
+	}
+
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err = os.Open(filepath.Join(root, "@v", escVersion+".info"))
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// latestFromList returns the latest version of a "list" file:
+// the highest release version, or the highest pre-release version if there is no release version.
+func latestFromList(listFile string) (string, error) {
+	file, err := os.Open(listFile)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	var versions []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		version, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+
+		versions = append(versions, version)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	latest := modver.Latest(versions)
+	if latest == "" {
+		return "", fmt.Errorf("%s: no versions: %w", listFile, fs.ErrNotExist)
+	}
+
+	return latest, nil
+}
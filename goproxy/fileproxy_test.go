@@ -0,0 +1,153 @@
+package goproxy
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ldez/grignotin/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFileProxy(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(dir)}).String()
+}
+
+func TestClient_fileProxy(t *testing.T) {
+	archive := testutil.CreateZip(t, map[string]string{
+		"github.com/ldez/grignotin@v0.1.0/go.mod": testModFile,
+	})
+
+	proxyURL := setupFileProxy(t, map[string]string{
+		"github.com/ldez/grignotin/@v/list":         "v0.1.0\nv0.2.0-rc.1\n",
+		"github.com/ldez/grignotin/@v/v0.1.0.info":  `{"Version":"v0.1.0","Time":"2020-01-01T00:00:00Z"}`,
+		"github.com/ldez/grignotin/@v/v0.1.0.mod":   testModFile,
+		"github.com/ldez/grignotin/@v/v0.1.0.zip":   string(archive),
+		"github.com/ldez/!grignotin/@v/list":        "v1.0.0\n",
+		"github.com/ldez/!grignotin/@v/v1.0.0.info": `{"Version":"v1.0.0","Time":"2021-01-01T00:00:00Z"}`,
+		"github.com/ldez/!grignotin/@v/v1.0.0.mod":  "module github.com/ldez/Grignotin\n",
+	})
+
+	client, err := NewClientFromProxyList(proxyURL)
+	require.NoError(t, err)
+
+	versions, err := client.GetVersions("github.com/ldez/grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.1.0", "v0.2.0-rc.1"}, versions)
+
+	info, err := client.GetInfo("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.1.0", info.Version)
+
+	// No "@latest" file: the latest release version of the list is used.
+	latest, err := client.GetLatest("github.com/ldez/grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.1.0", latest.Version)
+
+	mod, err := client.GetModFile("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "github.com/ldez/grignotin", mod.Module.Mod.Path)
+
+	sources, err := client.GetSources("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, archive, sources)
+
+	fsys, err := client.OpenSources("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	content, err := fs.ReadFile(fsys, "go.mod")
+	require.NoError(t, err)
+
+	assert.Equal(t, testModFile, string(content))
+
+	// Escaped module path.
+	mod, err = client.GetModFile("github.com/ldez/Grignotin", "v1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "github.com/ldez/Grignotin", mod.Module.Mod.Path)
+
+	_, err = client.GetInfo("github.com/ldez/grignotin", "v0.3.0")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestClient_fileProxy_fallback(t *testing.T) {
+	proxyURL := setupFileProxy(t, map[string]string{
+		"github.com/ldez/grignotin/@v/list": "v0.1.0\n",
+	})
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/other/@v/list",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = rw.Write([]byte("v1.0.0\n"))
+		})
+
+	client, err := NewClientFromProxyList(proxyURL + "," + server.URL)
+	require.NoError(t, err)
+
+	versions, err := client.GetVersions("github.com/ldez/grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.1.0"}, versions)
+
+	versions, err = client.GetVersions("github.com/ldez/other")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.0.0"}, versions)
+}
+
+func Test_latestFromList(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		list     string
+		expected string
+	}{
+		{
+			desc:     "release",
+			list:     "v1.0.0\nv1.10.0\nv1.2.0\nv2.0.0-rc.1\n",
+			expected: "v1.10.0",
+		},
+		{
+			desc:     "only pre-releases",
+			list:     "v1.0.0-alpha\nv1.0.0-beta\n",
+			expected: "v1.0.0-beta",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			listFile := filepath.Join(t.TempDir(), "list")
+			require.NoError(t, os.WriteFile(listFile, []byte(test.list), 0o600))
+
+			latest, err := latestFromList(listFile)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, latest)
+		})
+	}
+}
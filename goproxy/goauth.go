@@ -0,0 +1,519 @@
+package goproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GoAuthTransport HTTP transport that adds the credentials defined by GOAUTH.
+// https://pkg.go.dev/cmd/go#hdr-GOAUTH_environment_variable
+//
+// Like the go command:
+//   - the credentials are only sent over HTTPS.
+//   - the authentication commands are invoked without arguments before the first request.
+//   - when the server responds with a 4xx status code (except 404 and 410),
+//     the commands are invoked again with the URL of the request, and the request is retried once.
+type GoAuthTransport struct {
+	commands []authCommand
+
+	initOnce sync.Once
+	initErr  error
+
+	mu sync.RWMutex
+	// credentials indexed by URL prefix without scheme (e.g. "example.com/foo").
+	credentials map[string]http.Header
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// NewGoAuthTransport creates a new GoAuthTransport from a GOAUTH value.
+// An empty value is replaced by the default value of the go command: "netrc".
+func NewGoAuthTransport(goauth string) (*GoAuthTransport, error) {
+	commands, err := parseGoAuth(goauth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GOAUTH: %w", err)
+	}
+
+	return &GoAuthTransport{
+		commands:    commands,
+		credentials: make(map[string]http.Header),
+	}, nil
+}
+
+// RoundTrip executes a single HTTP transaction.
+func (t *GoAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" || len(t.commands) == 0 {
+		return t.transport().RoundTrip(req)
+	}
+
+	t.initOnce.Do(func() {
+		t.initErr = t.runCommands(context.WithoutCancel(req.Context()), "", nil)
+	})
+
+	if t.initErr != nil {
+		return nil, t.initErr
+	}
+
+	resp, err := t.transport().RoundTrip(t.enrich(req))
+	if err != nil || !shouldReauthenticate(resp) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, err
+	}
+
+	rawURL := req.URL.String()
+
+	// Clear the previous credentials and invoke the commands with the URL.
+	t.mu.Lock()
+	delete(t.credentials, trimScheme(rawURL))
+	t.mu.Unlock()
+
+	err = t.runCommands(req.Context(), rawURL, resp)
+
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	retryReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retryReq.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err = t.transport().RoundTrip(t.enrich(retryReq))
+
+	t.report(req.Context(), rawURL, err == nil && !shouldReauthenticate(resp))
+
+	return resp, err
+}
+
+// Wrap Wraps an HTTP client Transport with the GoAuthTransport.
+func (t *GoAuthTransport) Wrap(client *http.Client) *http.Client {
+	backup := client.Transport
+
+	t.Transport = backup
+	client.Transport = t
+
+	return client
+}
+
+// Client Creates a new HTTP client.
+func (t *GoAuthTransport) Client() *http.Client {
+	return &http.Client{
+		Transport: t,
+		Timeout:   30 * time.Second,
+	}
+}
+
+// enrich adds the stored credentials matching the longest prefix of the request URL.
+func (t *GoAuthTransport) enrich(req *http.Request) *http.Request {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	// Iteratively try prefixes, moving up the path hierarchy.
+	for prefix := trimScheme(req.URL.String()); prefix != "/" && prefix != "." && prefix != ""; prefix = path.Dir(prefix) {
+		if header, ok := t.credentials[prefix]; ok {
+			return withHeader(req, header)
+		}
+	}
+
+	// The machines of a .netrc file have no port.
+	if header, ok := t.credentials[req.URL.Hostname()]; ok {
+		return withHeader(req, header)
+	}
+
+	return req
+}
+
+func (t *GoAuthTransport) runCommands(ctx context.Context, rawURL string, resp *http.Response) error {
+	var errs []error
+
+	for _, command := range t.commands {
+		credentials, err := command.credentials(ctx, rawURL, resp)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		t.mu.Lock()
+
+		for prefix, header := range credentials {
+			prefix = trimScheme(prefix)
+
+			if len(header) == 0 {
+				delete(t.credentials, prefix)
+			} else {
+				t.credentials[prefix] = header
+			}
+		}
+
+		t.mu.Unlock()
+	}
+
+	return errors.Join(errs...)
+}
+
+// report tells the commands whether the credentials have been accepted.
+func (t *GoAuthTransport) report(ctx context.Context, rawURL string, accepted bool) {
+	for _, command := range t.commands {
+		if r, ok := command.(credentialsReporter); ok {
+			r.report(ctx, rawURL, accepted)
+		}
+	}
+}
+
+func (t *GoAuthTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+
+	return http.DefaultTransport
+}
+
+// authCommand is an authentication command of GOAUTH.
+type authCommand interface {
+	// credentials returns the headers to add to the requests, indexed by URL prefix.
+	// rawURL and resp are empty on the first invocation.
+	credentials(ctx context.Context, rawURL string, resp *http.Response) (map[string]http.Header, error)
+}
+
+// credentialsReporter is implemented by the commands that need to know whether the credentials have been accepted.
+type credentialsReporter interface {
+	report(ctx context.Context, rawURL string, accepted bool)
+}
+
+// parseGoAuth parses a GOAUTH value.
+func parseGoAuth(goauth string) ([]authCommand, error) {
+	if strings.TrimSpace(goauth) == "" {
+		// Same default as the go command.
+		goauth = "netrc"
+	}
+
+	var commands []authCommand
+
+	for command := range strings.SplitSeq(goauth, ";") {
+		words := strings.Fields(command)
+		if len(words) == 0 {
+			continue
+		}
+
+		switch words[0] {
+		case "off":
+			if len(words) != 1 || strings.TrimSpace(strings.ReplaceAll(goauth, ";", "")) != "off" {
+				return nil, errors.New("GOAUTH=off cannot be combined with other authentication commands")
+			}
+
+			return nil, nil
+
+		case "netrc":
+			if len(words) != 1 {
+				return nil, errors.New("GOAUTH=netrc does not accept arguments")
+			}
+
+			commands = append(commands, netrcCommand{})
+
+		case "git":
+			if len(words) != 2 {
+				return nil, errors.New("GOAUTH=git dir method requires an absolute path to the git working directory")
+			}
+
+			if !filepath.IsAbs(words[1]) {
+				return nil, fmt.Errorf("GOAUTH=git dir method requires an absolute path to the git working directory, dir is not absolute: %s", words[1])
+			}
+
+			commands = append(commands, &gitCommand{dir: words[1], fills: make(map[string][]byte)})
+
+		default:
+			commands = append(commands, execCommand{args: words})
+		}
+	}
+
+	return commands, nil
+}
+
+// netrcCommand uses the credentials from the .netrc file.
+type netrcCommand struct{}
+
+func (netrcCommand) credentials(_ context.Context, _ string, _ *http.Response) (map[string]http.Header, error) {
+	nrc, err := ReadNetrc()
+	if err != nil {
+		return nil, fmt.Errorf("GOAUTH=netrc: %w", err)
+	}
+
+	credentials := make(map[string]http.Header, len(nrc.Machines))
+
+	// Like the go command, the incomplete entries and the default entry are ignored.
+	for _, m := range slices.Backward(nrc.Machines) {
+		if m.Login == "" || m.Password == "" {
+			continue
+		}
+
+		credentials[m.Name] = basicAuthHeader(m.Login, m.Password)
+	}
+
+	return credentials, nil
+}
+
+// gitCommand uses the credentials from "git credential fill".
+type gitCommand struct {
+	dir string
+
+	mu sync.Mutex
+	// fills contains the outputs of "git credential fill" indexed by URL.
+	fills map[string][]byte
+}
+
+func (g *gitCommand) credentials(ctx context.Context, rawURL string, _ *http.Response) (map[string]http.Header, error) {
+	// Only run the git authenticator if the URL is given.
+	if rawURL == "" {
+		return nil, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Dir = g.dir
+	cmd.Stdin = strings.NewReader("url=" + rawURL + "\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("GOAUTH=git %s: %q: %w", g.dir, strings.Join(cmd.Args, " "), err)
+	}
+
+	prefix, username, password := parseGitAuth(out)
+	if prefix == "" {
+		prefix = rawURL
+	}
+
+	g.mu.Lock()
+	g.fills[rawURL] = out
+	g.mu.Unlock()
+
+	return map[string]http.Header{prefix: basicAuthHeader(username, password)}, nil
+}
+
+// report runs "git credential approve" or "git credential reject" to update the cache of the credential helper.
+func (g *gitCommand) report(ctx context.Context, rawURL string, accepted bool) {
+	g.mu.Lock()
+	out, ok := g.fills[rawURL]
+	delete(g.fills, rawURL)
+	g.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	action := "reject"
+	if accepted {
+		action = "approve"
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "credential", action)
+	cmd.Dir = g.dir
+	cmd.Stdin = bytes.NewReader(out)
+
+	_ = cmd.Run()
+}
+
+// parseGitAuth parses the output of "git credential fill".
+// https://git-scm.com/docs/git-credential#IOFMT
+func parseGitAuth(data []byte) (prefix, username, password string) {
+	u := &url.URL{}
+
+	for line := range strings.SplitSeq(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "protocol":
+			u.Scheme = value
+		case "host":
+			u.Host = value
+		case "path":
+			u.Path = value
+		case "username":
+			username = value
+		case "password":
+			password = value
+		case "url":
+			// Write to a local variable instead of updating u
+			// to accommodate a "url" field appearing before other fields.
+			parsed, err := url.Parse(value)
+			if err != nil {
+				continue
+			}
+
+			u = parsed
+		}
+	}
+
+	if u.Host == "" {
+		return "", username, password
+	}
+
+	return u.String(), username, password
+}
+
+// execCommand runs a user-defined command that prints the credentials.
+type execCommand struct {
+	args []string
+}
+
+func (e execCommand) credentials(ctx context.Context, rawURL string, resp *http.Response) (map[string]http.Header, error) {
+	args := e.args[1:]
+	if rawURL != "" {
+		args = append(slices.Clone(args), rawURL)
+	}
+
+	cmd := exec.CommandContext(ctx, e.args[0], args...) //nolint:gosec // The command is defined by the user.
+	if resp != nil {
+		cmd.Stdin = strings.NewReader(dumpResponseHeader(resp))
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("GOAUTH=%s: %w", strings.Join(e.args, " "), err)
+	}
+
+	credentials, err := parseUserAuth(string(out))
+	if err != nil {
+		return nil, fmt.Errorf("GOAUTH=%s: %w", strings.Join(e.args, " "), err)
+	}
+
+	return credentials, nil
+}
+
+// parseUserAuth parses the output of a GOAUTH command.
+//
+//	Response      = { CredentialSet } .
+//	CredentialSet = URLLine { URLLine } BlankLine { HeaderLine } BlankLine .
+//	URLLine       = /* URL that starts with "https://" */ '\n' .
+//	HeaderLine    = /* HTTP Request header */ '\n' .
+//	BlankLine     = '\n' .
+func parseUserAuth(data string) (map[string]http.Header, error) {
+	credentials := make(map[string]http.Header)
+
+	for data != "" {
+		var (
+			line string
+			ok   bool
+			urls []string
+		)
+
+		// Parse URLs first.
+		for {
+			line, data, ok = strings.Cut(data, "\n")
+			if !ok {
+				return nil, errors.New("invalid format: missing empty line after URLs")
+			}
+
+			if line == "" {
+				break
+			}
+
+			u, err := url.ParseRequestURI(line)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse URL %s: %w", line, err)
+			}
+
+			urls = append(urls, u.String())
+		}
+
+		// Parse headers second.
+		header := make(http.Header)
+
+		for {
+			line, data, ok = strings.Cut(data, "\n")
+			if !ok {
+				return nil, errors.New("invalid format: missing empty line after headers")
+			}
+
+			if line == "" {
+				break
+			}
+
+			name, value, found := strings.Cut(line, ": ")
+			if !found || name == "" || strings.ContainsAny(name, " \t") {
+				return nil, fmt.Errorf("invalid header line: %s", line)
+			}
+
+			header.Add(name, strings.TrimSpace(value))
+		}
+
+		// Associate the headers with each URL.
+		for _, u := range urls {
+			credentials[u] = header
+		}
+	}
+
+	return credentials, nil
+}
+
+// dumpResponseHeader writes the status line and the headers of the response,
+// in the format expected by the GOAUTH commands.
+func dumpResponseHeader(resp *http.Response) string {
+	b := &strings.Builder{}
+
+	_, _ = fmt.Fprintf(b, "%s %s\n", resp.Proto, resp.Status)
+
+	for _, key := range slices.Sorted(maps.Keys(resp.Header)) {
+		for _, value := range resp.Header[key] {
+			_, _ = fmt.Fprintf(b, "%s: %s\n", key, value)
+		}
+	}
+
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// shouldReauthenticate reports whether the response requires invoking the authentication commands with the URL.
+// 404 and 410 are the expected responses of a proxy for an unknown module.
+func shouldReauthenticate(resp *http.Response) bool {
+	return resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusGone
+}
+
+// withHeader returns a copy of the request with the headers replaced by the given values.
+func withHeader(req *http.Request, header http.Header) *http.Request {
+	enrichedReq := cloneRequest(req)
+
+	for key, values := range header {
+		enrichedReq.Header.Del(key)
+
+		for _, value := range values {
+			enrichedReq.Header.Add(key, value)
+		}
+	}
+
+	return enrichedReq
+}
+
+func basicAuthHeader(username, password string) http.Header {
+	req := &http.Request{Header: make(http.Header)}
+	req.SetBasicAuth(username, password)
+
+	return req.Header
+}
+
+// trimScheme removes the "https://" prefix to match the format used in .netrc files.
+func trimScheme(rawURL string) string {
+	return strings.TrimPrefix(rawURL, "https://")
+}
@@ -0,0 +1,234 @@
+package goproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoAuthTransport_RoundTrip_command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell script")
+	}
+
+	var calls int
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+
+		if req.Header.Get("Authorization") != "Bearer fresh" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = fmt.Fprint(rw, "ok")
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "stdin")
+
+	// Without arguments, the command returns stale credentials.
+	// With the URL as argument, the command returns fresh credentials and saves its stdin.
+	script := fmt.Sprintf(`#!/bin/sh
+if [ -z "$1" ]; then
+  printf '%%s\n\nAuthorization: Bearer stale\n\n' %q
+  exit 0
+fi
+cat > %q
+printf '%%s\n\nAuthorization: Bearer fresh\n\n' "$1"
+`, server.URL, output)
+
+	command := filepath.Join(dir, "auth.sh")
+	err := os.WriteFile(command, []byte(script), 0o700)
+	require.NoError(t, err)
+
+	transport, err := NewGoAuthTransport(command)
+	require.NoError(t, err)
+
+	transport.Transport = server.Client().Transport
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/foo", nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, calls)
+
+	stdin, err := os.ReadFile(output)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(stdin), "HTTP/1.1 401 Unauthorized\n")
+}
+
+func TestGoAuthTransport_RoundTrip_insecure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if _, _, ok := req.BasicAuth(); ok {
+			rw.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	netrc := filepath.Join(t.TempDir(), ".netrc")
+	err := os.WriteFile(netrc, []byte("machine 127.0.0.1 login user password secret\n"), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("NETRC", netrc)
+
+	transport, err := NewGoAuthTransport("netrc")
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func Test_parseGoAuth(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		goauth   string
+		expected []authCommand
+	}{
+		{
+			desc:     "default",
+			goauth:   "",
+			expected: []authCommand{netrcCommand{}},
+		},
+		{
+			desc:   "off",
+			goauth: "off",
+		},
+		{
+			desc:   "multiple commands",
+			goauth: "netrc; git /tmp/foo ;my-command --flag",
+			expected: []authCommand{
+				netrcCommand{},
+				&gitCommand{dir: "/tmp/foo", fills: map[string][]byte{}},
+				execCommand{args: []string{"my-command", "--flag"}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			commands, err := parseGoAuth(test.goauth)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, commands)
+		})
+	}
+}
+
+func Test_parseGoAuth_error(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		goauth string
+	}{
+		{
+			desc:   "off combined",
+			goauth: "off;netrc",
+		},
+		{
+			desc:   "netrc with arguments",
+			goauth: "netrc foo",
+		},
+		{
+			desc:   "git without directory",
+			goauth: "git",
+		},
+		{
+			desc:   "git with relative directory",
+			goauth: "git foo",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := parseGoAuth(test.goauth)
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_parseUserAuth(t *testing.T) {
+	data := `https://example.com
+https://example.net/api/
+
+Authorization: Basic dXNlcjpzZWNyZXQ=
+
+https://another-example.org/
+
+Example: Data
+Example: More
+
+`
+
+	credentials, err := parseUserAuth(data)
+	require.NoError(t, err)
+
+	expected := map[string]http.Header{
+		"https://example.com":          {"Authorization": {"Basic dXNlcjpzZWNyZXQ="}},
+		"https://example.net/api/":     {"Authorization": {"Basic dXNlcjpzZWNyZXQ="}},
+		"https://another-example.org/": {"Example": {"Data", "More"}},
+	}
+
+	assert.Equal(t, expected, credentials)
+}
+
+func Test_parseUserAuth_error(t *testing.T) {
+	testCases := []struct {
+		desc string
+		data string
+	}{
+		{
+			desc: "missing empty line after URLs",
+			data: "https://example.com\n",
+		},
+		{
+			desc: "missing empty line after headers",
+			data: "https://example.com\n\nAuthorization: Basic foo\n",
+		},
+		{
+			desc: "invalid URL",
+			data: "example.com\n\nAuthorization: Basic foo\n\n",
+		},
+		{
+			desc: "invalid header",
+			data: "https://example.com\n\nAuthorization\n\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := parseUserAuth(test.data)
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_parseGitAuth(t *testing.T) {
+	data := []byte("protocol=https\nhost=example.com\npath=foo/bar\nusername=user\npassword=secret\n")
+
+	prefix, username, password := parseGitAuth(data)
+
+	assert.Equal(t, "https://example.com/foo/bar", prefix)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "secret", password)
+}
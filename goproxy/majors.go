@@ -0,0 +1,142 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// majorProbeWindow is the number of major versions probed concurrently.
+// The discovery stops when no major version of a window exists.
+const majorProbeWindow = 4
+
+// MajorVersion is the latest version of a major version of a module.
+type MajorVersion struct {
+	// Path is the module path with the major version suffix (e.g. "github.com/foo/bar/v2", "gopkg.in/yaml.v3").
+	Path string
+	// Major is the major version of the latest version (e.g. "v2").
+	Major string
+	// Latest is the latest version of the module path.
+	Latest *VersionInfo
+	// Incompatible reports whether the latest version is a "+incompatible" version of the path without suffix
+	// (e.g. "v4.1.0+incompatible"): the major version can also exist with a suffix.
+	Incompatible bool
+}
+
+// GetMajorVersions gets the latest version of each major version of a module.
+// See [Client.GetMajorVersionsWithContext].
+func (c *Client) GetMajorVersions(moduleName string) ([]MajorVersion, error) {
+	return c.GetMajorVersionsWithContext(context.Background(), moduleName)
+}
+
+// GetMajorVersionsWithContext gets the latest version of each major version of a module.
+//
+// The proxy protocol cannot list the major versions:
+// the module paths with a major version suffix ("/v2", "/v3", ..., or ".v0", ".v1", ... for gopkg.in)
+// are probed concurrently with the "@latest" endpoint, until a window of successive major versions does not exist.
+// The major version suffix of moduleName is ignored.
+//
+// The major versions are sorted in ascending order.
+func (c *Client) GetMajorVersionsWithContext(ctx context.Context, moduleName string) ([]MajorVersion, error) {
+	err := module.CheckPath(moduleName)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, _, ok := module.SplitPathVersion(moduleName)
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid major version suffix", moduleName)
+	}
+
+	gopkgin := strings.HasPrefix(moduleName, "gopkg.in/")
+
+	// The major versions 0 and 1 of the paths without gopkg.in share the path without suffix.
+	first := 1
+	if gopkgin {
+		first = 0
+	}
+
+	var majors []MajorVersion
+
+	for start := first; ; start += majorProbeWindow {
+		var found []MajorVersion
+
+		found, err = c.probeMajors(ctx, prefix, start, gopkgin)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(found) == 0 {
+			return majors, nil
+		}
+
+		majors = append(majors, found...)
+	}
+}
+
+// probeMajors gets concurrently the latest versions of a window of major versions.
+func (c *Client) probeMajors(ctx context.Context, prefix string, start int, gopkgin bool) ([]MajorVersion, error) {
+	results := make([]*MajorVersion, majorProbeWindow)
+	errs := make([]error, majorProbeWindow)
+
+	var wg sync.WaitGroup
+
+	for i := range majorProbeWindow {
+		wg.Go(func() {
+			modulePath := majorPath(prefix, start+i, gopkgin)
+
+			info, err := c.GetLatestWithContext(ctx, modulePath)
+
+			switch {
+			case err == nil:
+				results[i] = &MajorVersion{
+					Path:         modulePath,
+					Major:        semver.Major(info.Version),
+					Latest:       info,
+					Incompatible: isIncompatible(info.Version),
+				}
+
+			case !isNotExist(err):
+				errs[i] = err
+			}
+		})
+	}
+
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var majors []MajorVersion
+
+	for _, result := range results {
+		if result != nil {
+			majors = append(majors, *result)
+		}
+	}
+
+	return majors, nil
+}
+
+// majorPath returns the module path of a major version.
+// The major versions 0 and 1 are the path without suffix (except for gopkg.in).
+func majorPath(prefix string, major int, gopkgin bool) string {
+	switch {
+	case gopkgin:
+		return prefix + ".v" + strconv.Itoa(major)
+
+	case major <= 1:
+		return prefix
+
+	default:
+		return prefix + "/v" + strconv.Itoa(major)
+	}
+}
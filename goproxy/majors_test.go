@@ -0,0 +1,128 @@
+package goproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetMajorVersions(t *testing.T) {
+	latest := map[string]string{
+		"/github.com/ldez/grignotin/@latest":    "v1.3.0",
+		"/github.com/ldez/grignotin/v2/@latest": "v2.1.0",
+		"/github.com/ldez/grignotin/v3/@latest": "v3.0.0",
+		"/github.com/ldez/grignotin/v6/@latest": "v6.0.0-rc.1",
+		"/github.com/ldez/legacy/@latest":       "v4.1.0+incompatible",
+		"/github.com/ldez/legacy/v5/@latest":    "v5.0.0",
+		"/gopkg.in/yaml.v2/@latest":             "v2.4.0",
+		"/gopkg.in/yaml.v3/@latest":             "v3.0.1",
+		"/gopkg.in/foo.v0/@latest":              "v0.1.0",
+		"/gopkg.in/foo.v1/@latest":              "v1.0.0",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		version, ok := latest[req.URL.Path]
+		if !ok {
+			http.Error(rw, "not found: module "+req.URL.Path+": no matching versions for query \"latest\"", http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = fmt.Fprintf(rw, `{"Version":%q,"Time":"2024-11-12T19:41:09Z"}`, version)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+
+	testCases := []struct {
+		desc       string
+		moduleName string
+		expected   []string
+	}{
+		{
+			desc:       "major version suffixes",
+			moduleName: "github.com/ldez/grignotin",
+			expected: []string{
+				"github.com/ldez/grignotin@v1.3.0",
+				"github.com/ldez/grignotin/v2@v2.1.0",
+				"github.com/ldez/grignotin/v3@v3.0.0",
+				"github.com/ldez/grignotin/v6@v6.0.0-rc.1",
+			},
+		},
+		{
+			desc:       "from a major version",
+			moduleName: "github.com/ldez/grignotin/v3",
+			expected: []string{
+				"github.com/ldez/grignotin@v1.3.0",
+				"github.com/ldez/grignotin/v2@v2.1.0",
+				"github.com/ldez/grignotin/v3@v3.0.0",
+				"github.com/ldez/grignotin/v6@v6.0.0-rc.1",
+			},
+		},
+		{
+			desc:       "gopkg.in",
+			moduleName: "gopkg.in/yaml.v2",
+			expected: []string{
+				"gopkg.in/yaml.v2@v2.4.0",
+				"gopkg.in/yaml.v3@v3.0.1",
+			},
+		},
+		{
+			desc:       "gopkg.in major version 0",
+			moduleName: "gopkg.in/foo.v1",
+			expected: []string{
+				"gopkg.in/foo.v0@v0.1.0",
+				"gopkg.in/foo.v1@v1.0.0",
+			},
+		},
+		{
+			desc:       "incompatible version",
+			moduleName: "github.com/ldez/legacy",
+			expected: []string{
+				"github.com/ldez/legacy@v4.1.0+incompatible",
+				"github.com/ldez/legacy/v5@v5.0.0",
+			},
+		},
+		{
+			desc:       "unknown module",
+			moduleName: "github.com/ldez/unknown",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			majors, err := client.GetMajorVersions(test.moduleName)
+			require.NoError(t, err)
+
+			var versions []string
+
+			for _, major := range majors {
+				assert.Equal(t, major.Major, major.Latest.Version[:2])
+				assert.Equal(t, strings.HasSuffix(major.Latest.Version, "+incompatible"), major.Incompatible)
+
+				versions = append(versions, major.Path+"@"+major.Latest.Version)
+			}
+
+			assert.Equal(t, test.expected, versions)
+		})
+	}
+}
+
+func TestClient_GetMajorVersions_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "forbidden", http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+
+	_, err := client.GetMajorVersions("github.com/ldez/grignotin")
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
@@ -0,0 +1,305 @@
+package goproxy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Netrc is the content of a .netrc file.
+// https://www.gnu.org/software/inetutils/manual/html_node/The-_002enetrc-file.html
+type Netrc struct {
+	Machines []NetrcMachine
+
+	// Default is the "default" entry: it matches any machine.
+	Default *NetrcMachine
+}
+
+// NetrcMachine is an entry of a .netrc file.
+type NetrcMachine struct {
+	// Name is the name of the machine (empty for the "default" entry).
+	Name     string
+	Login    string
+	Password string
+	Account  string
+}
+
+// Machine returns the entry of the machine,
+// or the "default" entry if there is no entry for the machine.
+// Returns nil if there is no matching entry.
+func (n *Netrc) Machine(name string) *NetrcMachine {
+	for i := range n.Machines {
+		if n.Machines[i].Name == name {
+			return &n.Machines[i]
+		}
+	}
+
+	return n.Default
+}
+
+// ReadNetrc reads the .netrc file of the user:
+// the file defined by the NETRC environment variable, or the file inside the home directory.
+// A missing file is not an error.
+func ReadNetrc() (*Netrc, error) {
+	p, err := netrcPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filepath.Clean(p))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Netrc{}, nil
+		}
+
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	return ParseNetrc(file)
+}
+
+// ParseNetrc parses the content of a .netrc file.
+//
+// The tokens can be quoted with double quotes (a backslash escapes the next character),
+// the lines starting with "#" are comments,
+// and the content of the macros ("macdef") is ignored.
+func ParseNetrc(r io.Reader) (*Netrc, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := tokenizeNetrc(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	nrc := &Netrc{}
+
+	// current is the entry being parsed: an index of Machines, or -1 for the default entry.
+	current := -2
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		if token == "default" {
+			if nrc.Default != nil {
+				// There can be only one default token.
+				break
+			}
+
+			nrc.Default = &NetrcMachine{}
+			current = -1
+
+			continue
+		}
+
+		if i+1 >= len(tokens) {
+			return nil, fmt.Errorf("netrc: missing value for %q", token)
+		}
+
+		i++
+		value := tokens[i]
+
+		switch token {
+		case "machine":
+			if nrc.Default != nil {
+				// The default token must be after all machine tokens.
+				return nrc, nil
+			}
+
+			nrc.Machines = append(nrc.Machines, NetrcMachine{Name: value})
+			current = len(nrc.Machines) - 1
+
+		case "login", "password", "account":
+			err = nrc.setField(current, token, value)
+			if err != nil {
+				return nil, err
+			}
+
+		default:
+			// The macros have already been removed, and the unknown tokens are ignored.
+		}
+	}
+
+	return nrc, nil
+}
+
+// setField sets the login, the password, or the account of an entry.
+func (n *Netrc) setField(index int, token, value string) error {
+	entry := n.entry(index)
+	if entry == nil {
+		return fmt.Errorf("netrc: %q outside of a machine entry", token)
+	}
+
+	switch token {
+	case "login":
+		entry.Login = value
+	case "password":
+		entry.Password = value
+	default:
+		entry.Account = value
+	}
+
+	return nil
+}
+
+func (n *Netrc) entry(index int) *NetrcMachine {
+	switch {
+	case index == -1:
+		return n.Default
+	case index >= 0:
+		return &n.Machines[index]
+	default:
+		return nil
+	}
+}
+
+// tokenizeNetrc splits the content of a .netrc file into tokens.
+// The body of the macros is skipped: it begins with the next line and continues until a null line.
+func tokenizeNetrc(data string) ([]string, error) {
+	var tokens []string
+
+	var expectMacroName, inMacro bool
+
+	for i := 0; i < len(data); {
+		c := data[i]
+
+		switch {
+		case c == '\n':
+			i++
+
+			if inMacro {
+				inMacro = false
+				i += skipMacro(data[i:])
+			}
+
+			continue
+
+		case isNetrcSpace(c):
+			i++
+
+			continue
+
+		case c == '#' && (i == 0 || data[i-1] == '\n'):
+			if end := strings.IndexByte(data[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(data)
+			}
+
+			continue
+		}
+
+		token, n, err := readToken(data[i:])
+		if err != nil {
+			return nil, err
+		}
+
+		i += n
+
+		tokens = append(tokens, token)
+
+		switch {
+		case expectMacroName:
+			expectMacroName = false
+			inMacro = true
+
+		case token == "macdef":
+			expectMacroName = true
+		}
+	}
+
+	return tokens, nil
+}
+
+// skipMacro returns the length of the body of a macro, including the terminating null line.
+func skipMacro(data string) int {
+	n := 0
+
+	for n < len(data) {
+		line := data[n:]
+
+		end := strings.IndexByte(line, '\n')
+		if end < 0 {
+			return len(data)
+		}
+
+		n += end + 1
+
+		if strings.TrimRight(line[:end], "\r") == "" {
+			break
+		}
+	}
+
+	return n
+}
+
+// readToken reads a token, quoted or not.
+// Returns the unquoted token and the number of bytes read.
+func readToken(data string) (string, int, error) {
+	if data[0] == '"' {
+		return readQuotedToken(data)
+	}
+
+	n := 0
+	for n < len(data) && !isNetrcSpace(data[n]) && data[n] != '\n' {
+		n++
+	}
+
+	return data[:n], n, nil
+}
+
+// readQuotedToken reads a token surrounded by double quotes.
+// Returns the unquoted token and the number of bytes read.
+func readQuotedToken(data string) (string, int, error) {
+	b := &strings.Builder{}
+
+	for i := 1; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			return b.String(), i + 1, nil
+
+		case '\\':
+			i++
+			if i < len(data) {
+				b.WriteByte(data[i])
+			}
+
+		default:
+			b.WriteByte(data[i])
+		}
+	}
+
+	return "", 0, errors.New("netrc: unterminated quoted token")
+}
+
+func isNetrcSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// netrcPath returns the path of the .netrc file: the NETRC environment variable or the file inside the home directory.
+func netrcPath() (string, error) {
+	if env := os.Getenv("NETRC"); env != "" {
+		return env, nil
+	}
+
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	base := ".netrc"
+	if runtime.GOOS == "windows" {
+		base = "_netrc"
+	}
+
+	return filepath.Join(dir, base), nil
+}
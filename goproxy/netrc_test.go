@@ -0,0 +1,134 @@
+package goproxy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetrc(t *testing.T) {
+	data := `machine api.github.com
+  login user
+  password pwd
+
+machine incomplete.host
+  login justlogin
+
+# a comment
+machine test.host
+login user2
+password pwd2 account acc
+
+machine oneline login user3 password pwd3
+
+machine ignore.host macdef ignore
+  login nobody
+  password nothing
+
+machine hasmacro.too macdef ignore-next-lines login user4 password pwd4
+  login nobody
+  password nothing
+
+machine quoted.host login "my user" password "p\"w d"
+
+default
+login anonymous
+password gopher@golang.org
+
+machine after.default
+login oops
+password too-late-in-file
+`
+
+	nrc, err := ParseNetrc(strings.NewReader(data))
+	require.NoError(t, err)
+
+	expected := &Netrc{
+		Machines: []NetrcMachine{
+			{Name: "api.github.com", Login: "user", Password: "pwd"},
+			{Name: "incomplete.host", Login: "justlogin"},
+			{Name: "test.host", Login: "user2", Password: "pwd2", Account: "acc"},
+			{Name: "oneline", Login: "user3", Password: "pwd3"},
+			{Name: "ignore.host"},
+			{Name: "hasmacro.too", Login: "user4", Password: "pwd4"},
+			{Name: "quoted.host", Login: "my user", Password: `p"w d`},
+		},
+		Default: &NetrcMachine{Login: "anonymous", Password: "gopher@golang.org"},
+	}
+
+	assert.Equal(t, expected, nrc)
+}
+
+func TestParseNetrc_error(t *testing.T) {
+	testCases := []struct {
+		desc string
+		data string
+	}{
+		{
+			desc: "missing value",
+			data: "machine example.com login",
+		},
+		{
+			desc: "unterminated quote",
+			data: `machine example.com login "user`,
+		},
+		{
+			desc: "outside of machine",
+			data: "login user password secret",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := ParseNetrc(strings.NewReader(test.data))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNetrc_Machine(t *testing.T) {
+	nrc := &Netrc{
+		Machines: []NetrcMachine{
+			{Name: "example.com", Login: "user"},
+			{Name: "example.com", Login: "other"},
+		},
+		Default: &NetrcMachine{Login: "anonymous"},
+	}
+
+	assert.Equal(t, &NetrcMachine{Name: "example.com", Login: "user"}, nrc.Machine("example.com"))
+	assert.Equal(t, &NetrcMachine{Login: "anonymous"}, nrc.Machine("example.org"))
+
+	nrc.Default = nil
+
+	assert.Nil(t, nrc.Machine("example.org"))
+}
+
+func TestReadNetrc(t *testing.T) {
+	netrc := filepath.Join(t.TempDir(), ".netrc")
+	err := os.WriteFile(netrc, []byte("machine example.com login user password secret\n"), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("NETRC", netrc)
+
+	nrc, err := ReadNetrc()
+	require.NoError(t, err)
+
+	expected := &Netrc{
+		Machines: []NetrcMachine{{Name: "example.com", Login: "user", Password: "secret"}},
+	}
+
+	assert.Equal(t, expected, nrc)
+}
+
+func TestReadNetrc_missing(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+
+	nrc, err := ReadNetrc()
+	require.NoError(t, err)
+
+	assert.Equal(t, &Netrc{}, nrc)
+}
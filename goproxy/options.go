@@ -0,0 +1,228 @@
+package goproxy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/ldez/grignotin/internal/proxylist"
+)
+
+const defaultProxyURL = "https://proxy.golang.org"
+
+// Default timeouts of the HTTP requests.
+const (
+	// DefaultTimeout is the default timeout of the requests to the list, latest, info, and mod endpoints.
+	DefaultTimeout = 10 * time.Second
+	// DefaultZipTimeout is the default timeout of the requests to the zip endpoint.
+	DefaultZipTimeout = 5 * time.Minute
+)
+
+// Endpoint is a kind of resource of the module proxy protocol.
+type Endpoint string
+
+// Endpoints of the module proxy protocol.
+const (
+	EndpointList   Endpoint = "list"   // <module>/@v/list
+	EndpointLatest Endpoint = "latest" // <module>/@latest
+	EndpointInfo   Endpoint = "info"   // <module>/@v/<version>.info
+	EndpointMod    Endpoint = "mod"    // <module>/@v/<version>.mod
+	EndpointZip    Endpoint = "zip"    // <module>/@v/<version>.zip
+)
+
+var defaultTimeouts = map[Endpoint]time.Duration{
+	EndpointList:   DefaultTimeout,
+	EndpointLatest: DefaultTimeout,
+	EndpointInfo:   DefaultTimeout,
+	EndpointMod:    DefaultTimeout,
+	EndpointZip:    DefaultZipTimeout,
+}
+
+// Option configures a [Client].
+type Option func(c *Client) error
+
+// New creates a new Client.
+// By default, the client uses the proxy "https://proxy.golang.org",
+// with the timeouts [DefaultTimeout] and [DefaultZipTimeout].
+func New(opts ...Option) (*Client, error) {
+	client := newClient()
+
+	err := WithProxyURL(defaultProxyURL)(client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		err = opt(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+func newClient() *Client {
+	return &Client{
+		HTTPClient: &http.Client{},
+		Timeouts:   maps.Clone(defaultTimeouts),
+	}
+}
+
+// WithProxyURL uses a single proxy (http, https, or file URL).
+// An empty value is replaced by "https://proxy.golang.org".
+func WithProxyURL(proxyURL string) Option {
+	return func(c *Client) error {
+		if proxyURL == "" {
+			proxyURL = defaultProxyURL
+		}
+
+		u, err := proxylist.ParseURL(proxyURL)
+		if err != nil {
+			return err
+		}
+
+		c.proxies = []proxySpec{{name: u.String(), url: u}}
+
+		return nil
+	}
+}
+
+// WithProxyList uses a list of proxies (GOPROXY value).
+// An empty value is replaced by the default value of the go command: "https://proxy.golang.org,direct".
+// See [NewClientFromProxyList].
+func WithProxyList(goproxy string) Option {
+	return func(c *Client) error {
+		if goproxy == "" {
+			goproxy = defaultProxyList
+		}
+
+		proxies, err := parseProxyList(goproxy)
+		if err != nil {
+			return fmt.Errorf("invalid GOPROXY: %w", err)
+		}
+
+		c.proxies = proxies
+
+		return nil
+	}
+}
+
+// WithHTTPClient uses the HTTP client.
+// The timeout of the HTTP client applies to all the requests, in addition to the timeouts by endpoint.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		if client == nil {
+			return errors.New("HTTP client missing")
+		}
+
+		c.HTTPClient = client
+
+		return nil
+	}
+}
+
+// WithTransport uses the transport for the HTTP requests.
+// The HTTP client is copied: a client defined by [WithHTTPClient] is not modified.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) error {
+		if transport == nil {
+			return errors.New("transport missing")
+		}
+
+		client := *c.HTTPClient
+		client.Transport = transport
+
+		c.HTTPClient = &client
+
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the HTTP requests.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.UserAgent = userAgent
+
+		return nil
+	}
+}
+
+// WithHeader adds a header to the HTTP requests.
+func WithHeader(key, value string) Option {
+	return func(c *Client) error {
+		if c.Header == nil {
+			c.Header = http.Header{}
+		}
+
+		c.Header.Add(key, value)
+
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of the requests to all the endpoints.
+// Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		for _, endpoint := range []Endpoint{EndpointList, EndpointLatest, EndpointInfo, EndpointMod, EndpointZip} {
+			err := WithEndpointTimeout(endpoint, timeout)(c)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithEndpointTimeout sets the timeout of the requests to an endpoint.
+// Zero means no timeout.
+func WithEndpointTimeout(endpoint Endpoint, timeout time.Duration) Option {
+	return func(c *Client) error {
+		if _, ok := defaultTimeouts[endpoint]; !ok {
+			return fmt.Errorf("unknown endpoint: %q", endpoint)
+		}
+
+		if timeout < 0 {
+			return fmt.Errorf("negative timeout: %s", timeout)
+		}
+
+		if c.Timeouts == nil {
+			c.Timeouts = map[Endpoint]time.Duration{}
+		}
+
+		c.Timeouts[endpoint] = timeout
+
+		return nil
+	}
+}
+
+// WithMaxResponseSize sets the maximum size (in bytes) of a response body.
+// Zero means no limit.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *Client) error {
+		if size < 0 {
+			return fmt.Errorf("negative max response size: %d", size)
+		}
+
+		c.MaxResponseSize = size
+
+		return nil
+	}
+}
+
+// WithBatchConcurrency sets the maximum number of concurrent requests of a batch.
+func WithBatchConcurrency(concurrency int) Option {
+	return func(c *Client) error {
+		if concurrency <= 0 {
+			return fmt.Errorf("invalid batch concurrency: %d", concurrency)
+		}
+
+		c.BatchConcurrency = concurrency
+
+		return nil
+	}
+}
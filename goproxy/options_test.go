@@ -0,0 +1,244 @@
+package goproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Second}
+
+	client, err := New(WithProxyList("https://example.com|direct"), WithHTTPClient(httpClient))
+	require.NoError(t, err)
+
+	assert.Same(t, httpClient, client.HTTPClient)
+	require.Len(t, client.proxies, 2)
+	assert.Equal(t, "https://example.com", client.proxies[0].name)
+	assert.Equal(t, ProxyDirect, client.proxies[1].name)
+}
+
+func TestNew_default(t *testing.T) {
+	client, err := New()
+	require.NoError(t, err)
+
+	require.Len(t, client.proxies, 1)
+	assert.Equal(t, defaultProxyURL, client.proxies[0].name)
+
+	assert.Equal(t, DefaultTimeout, client.Timeouts[EndpointInfo])
+	assert.Equal(t, DefaultZipTimeout, client.Timeouts[EndpointZip])
+}
+
+func TestNew_errors(t *testing.T) {
+	testCases := []struct {
+		desc string
+		opt  Option
+	}{
+		{
+			desc: "invalid proxy URL",
+			opt:  WithProxyURL("://example.com"),
+		},
+		{
+			desc: "unsupported scheme",
+			opt:  WithProxyURL("ftp://example.com"),
+		},
+		{
+			desc: "invalid proxy list",
+			opt:  WithProxyList("https://example.com,ftp://example.com"),
+		},
+		{
+			desc: "nil HTTP client",
+			opt:  WithHTTPClient(nil),
+		},
+		{
+			desc: "nil transport",
+			opt:  WithTransport(nil),
+		},
+		{
+			desc: "unknown endpoint",
+			opt:  WithEndpointTimeout("foo", time.Second),
+		},
+		{
+			desc: "negative timeout",
+			opt:  WithTimeout(-time.Second),
+		},
+		{
+			desc: "negative max response size",
+			opt:  WithMaxResponseSize(-1),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(test.opt)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNewClient_invalidURL(t *testing.T) {
+	client := NewClient("://example.com")
+
+	_, err := client.GetVersions("github.com/ldez/grignotin")
+	require.Error(t, err)
+
+	_, err = client.GetInfo("github.com/ldez/grignotin", "v0.1.0")
+	require.Error(t, err)
+}
+
+func TestWithUserAgent(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+		func(rw http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "grignotin-test", req.UserAgent())
+			assert.Equal(t, "team-a", req.Header.Get("X-Team"))
+			assert.Equal(t, []string{"a", "b"}, req.Header.Values("X-Values"))
+
+			_, _ = fmt.Fprint(rw, "v0.1.0\n")
+		})
+
+	client, err := New(
+		WithProxyURL(server.URL),
+		WithUserAgent("grignotin-test"),
+		WithHeader("X-Team", "team-a"),
+		WithHeader("X-Values", "a"),
+		WithHeader("X-Values", "b"),
+	)
+	require.NoError(t, err)
+
+	versions, err := client.GetVersions("github.com/ldez/grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.1.0"}, versions)
+}
+
+func TestWithTransport(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Second}
+
+	var called bool
+
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	})
+
+	client, err := New(WithHTTPClient(httpClient), WithTransport(transport))
+	require.NoError(t, err)
+
+	_, err = client.GetVersions("github.com/ldez/grignotin")
+	require.NoError(t, err)
+
+	assert.True(t, called)
+	assert.Nil(t, httpClient.Transport)
+	assert.Equal(t, time.Second, client.HTTPClient.Timeout)
+}
+
+func TestWithEndpointTimeout(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+		func(rw http.ResponseWriter, req *http.Request) {
+			select {
+			case <-time.After(time.Second):
+			case <-req.Context().Done():
+			}
+
+			_, _ = fmt.Fprint(rw, "v0.1.0\n")
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.mod",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+
+			_, _ = fmt.Fprint(rw, "module github.com/ldez/grignotin\n")
+		})
+
+	client, err := New(
+		WithProxyURL(server.URL),
+		WithTimeout(time.Second),
+		WithEndpointTimeout(EndpointList, 10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetVersions("github.com/ldez/grignotin")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.GetRawModFile("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+}
+
+func TestWithMaxResponseSize(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.mod",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(rw, "module github.com/ldez/grignotin\n")
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.zip",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			// Without Content-Length.
+			for range 10 {
+				_, _ = fmt.Fprint(rw, strings.Repeat("a", 10))
+
+				rw.(http.Flusher).Flush()
+			}
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			// Without Content-Length.
+			for i := range 50 {
+				_, _ = fmt.Fprintf(rw, "v0.%d.0\n", i)
+
+				rw.(http.Flusher).Flush()
+			}
+		})
+
+	client, err := New(WithProxyURL(server.URL), WithMaxResponseSize(int64(len("module github.com/ldez/grignotin\n"))))
+	require.NoError(t, err)
+
+	_, err = client.GetRawModFile("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	_, err = client.GetSources("github.com/ldez/grignotin", "v0.1.0")
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+
+	// The list is not truncated.
+	versions, err := client.GetVersions("github.com/ldez/grignotin")
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+
+	assert.Nil(t, versions)
+
+	client.MaxResponseSize = 10
+
+	_, err = client.GetRawModFile("github.com/ldez/grignotin", "v0.1.0")
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,70 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+
+	"github.com/ldez/grignotin/internal/proxylist"
+)
+
+// defaultProxyList is the default value of GOPROXY used by the go command.
+const defaultProxyList = "https://proxy.golang.org,direct"
+
+// Special elements of a GOPROXY list.
+const (
+	// ProxyOff disallows downloading from any source.
+	ProxyOff = proxylist.Off
+	// ProxyDirect downloads directly from version control repositories.
+	ProxyDirect = proxylist.Direct
+)
+
+// ErrProxyOff is returned when the "off" element of the proxy list is reached.
+var ErrProxyOff = errors.New("module lookup disabled by GOPROXY=off")
+
+// ErrDirectUnavailable is returned when the "direct" element of the proxy list is reached,
+// but no [DirectFunc] is defined on the client.
+var ErrDirectUnavailable = errors.New("direct module lookup is not available")
+
+// DirectFunc fetches a resource of the module proxy protocol without using a proxy.
+// It is called when the "direct" element of the proxy list is reached.
+//
+// The moduleName is not escaped,
+// and the endpoint is relative to the module with an escaped version (e.g. "@v/list", "@v/v1.0.0-!r!c1.info", "@latest").
+//
+// An error matching [fs.ErrNotExist] is handled like a 404 or 410 response from a proxy.
+type DirectFunc func(ctx context.Context, moduleName, endpoint string) (io.ReadCloser, error)
+
+// proxySpec is an element of a GOPROXY list.
+type proxySpec struct {
+	// name is "off", "direct", or the URL of the proxy.
+	name string
+	url  *url.URL
+
+	// fallBackOnError is true if the next proxy must be tried on any error (separator "|"),
+	// otherwise only on 404 and 410 responses (separator ",").
+	fallBackOnError bool
+}
+
+// parseProxyList parses a GOPROXY value.
+func parseProxyList(goproxy string) ([]proxySpec, error) {
+	list, err := proxylist.Parse(goproxy)
+	if err != nil {
+		return nil, err
+	}
+
+	proxies := make([]proxySpec, 0, len(list))
+
+	for _, p := range list {
+		proxies = append(proxies, proxySpec{name: p.Name, url: p.URL, fallBackOnError: p.FallBackOnError})
+	}
+
+	return proxies, nil
+}
+
+// isNotExist reports whether the error allows falling back to the next proxy of a "," separated list.
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
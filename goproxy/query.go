@@ -0,0 +1,216 @@
+package goproxy
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Version queries with a special meaning.
+const (
+	QueryLatest  = "latest"
+	QueryUpgrade = "upgrade"
+	QueryPatch   = "patch"
+)
+
+// Query resolves a version query, like "go get <module>@<query>".
+// See [Client.QueryWithContext].
+func (c *Client) Query(moduleName, query, current string) (*VersionInfo, error) {
+	return c.QueryWithContext(context.Background(), moduleName, query, current)
+}
+
+// QueryWithContext resolves a version query, like "go get <module>@<query>".
+// https://go.dev/ref/mod#version-queries
+//
+// The supported queries are:
+//   - "latest": the highest release version, or pre-release version, or pseudo-version.
+//   - "upgrade": like "latest", but the current version is kept if it is higher.
+//   - "patch": the latest version with the same major and minor version as the current version.
+//   - a version prefix (e.g. "v1", "v1.2"): the highest release version with the prefix,
+//     or pre-release version if no release version matches.
+//   - a comparison (e.g. "<v2", "<=v1.2.3", ">v1.2.3", ">=v1.2"):
+//     the version closest to the comparison target, release versions are preferred over pre-release versions.
+//   - a complete semantic version (e.g. "v1.2.3"): the version itself, even if it is retracted.
+//   - a revision (e.g. a branch name, a tag, or a commit hash): resolved by the proxy.
+//
+// The retracted versions are excluded, except for a complete semantic version.
+// The "+incompatible" versions are only used if the module does not have other versions.
+//
+// The current version is the version currently required (can be empty).
+// It is only used by the "upgrade" and "patch" queries.
+func (c *Client) QueryWithContext(ctx context.Context, moduleName, query, current string) (*VersionInfo, error) {
+	switch {
+	case query == "":
+		return nil, fmt.Errorf("%s: %w: empty query", moduleName, ErrInvalidVersion)
+
+	case query == "none":
+		return nil, fmt.Errorf("%s: query %q is not supported", moduleName, query)
+
+	case isListQuery(query):
+		list, err := c.GetVersionListWithContext(ctx, moduleName, VersionListOptions{IncludeIncompatible: true})
+		if err != nil {
+			return nil, err
+		}
+
+		// Simplification of the go command:
+		// the "+incompatible" versions are only allowed if there is no compatible version.
+		if slices.ContainsFunc(list.Versions, func(v string) bool { return !isIncompatible(v) }) {
+			list.Versions = slices.DeleteFunc(list.Versions, isIncompatible)
+		}
+
+		version, err := resolveQuery(list, query, current)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GetInfoWithContext(ctx, moduleName, version)
+
+	case semver.IsValid(query):
+		return c.GetInfoWithContext(ctx, moduleName, module.CanonicalVersion(query))
+
+	default:
+		return c.GetInfoWithContext(ctx, moduleName, query)
+	}
+}
+
+// isListQuery reports whether the query is resolved with the list of the versions.
+func isListQuery(query string) bool {
+	switch {
+	case query == QueryLatest, query == QueryUpgrade, query == QueryPatch:
+		return true
+
+	case strings.HasPrefix(query, "<"), strings.HasPrefix(query, ">"):
+		return true
+
+	default:
+		return semver.IsValid(query) && isSemverPrefix(query)
+	}
+}
+
+// resolveQuery selects the version matching the query inside the list.
+func resolveQuery(list *VersionList, query, current string) (string, error) {
+	var version string
+
+	switch query {
+	case QueryLatest:
+		version = list.Latest()
+
+	case QueryUpgrade:
+		version = list.Upgrade(current)
+
+	case QueryPatch:
+		if current == "" {
+			return "", fmt.Errorf("can't query version %q of module %s: no existing version is required", query, list.Module)
+		}
+
+		version = list.Patch(current)
+
+	default:
+		match, preferLower, err := parseQuery(query)
+		if err != nil {
+			return "", fmt.Errorf("%s@%s: %w", list.Module, query, err)
+		}
+
+		version = selectVersion(list.Versions, match, preferLower)
+	}
+
+	if version == "" {
+		return "", fmt.Errorf("module %s: no matching versions for query %q: %w", list.Module, query, fs.ErrNotExist)
+	}
+
+	return version, nil
+}
+
+// parseQuery parses a version prefix or a comparison.
+// The lowest matching version is preferred for the ">" and ">=" comparisons.
+func parseQuery(query string) (match func(v string) bool, preferLower bool, err error) {
+	var op, target string
+
+	for _, o := range []string{"<=", ">=", "<", ">"} {
+		if t, ok := strings.CutPrefix(query, o); ok {
+			op, target = o, t
+
+			break
+		}
+	}
+
+	if op == "" {
+		// Version prefix (e.g. "v1.2"): the pre-release versions of the prefix itself (e.g. "v1.2.0-rc.1") are excluded.
+		return func(v string) bool {
+			return strings.HasPrefix(v, query+".") && semver.Compare(v, query) >= 0
+		}, false, nil
+	}
+
+	if !semver.IsValid(target) {
+		return nil, false, fmt.Errorf("%w: invalid semantic version %q in range %q", ErrInvalidVersion, target, query)
+	}
+
+	switch op {
+	case "<=":
+		// "<=v1.2" is ambiguous: "v1.2" can mean "v1.2.3".
+		if isSemverPrefix(target) {
+			return nil, false, fmt.Errorf("%w: ambiguous semantic version %q in range %q", ErrInvalidVersion, target, query)
+		}
+
+		return func(v string) bool { return semver.Compare(v, target) <= 0 }, false, nil
+
+	case "<":
+		return func(v string) bool { return semver.Compare(v, target) < 0 }, false, nil
+
+	case ">=":
+		return func(v string) bool { return semver.Compare(v, target) >= 0 }, true, nil
+
+	default:
+		// ">v1.2" is ambiguous: "v1.2" can mean "v1.2.3".
+		if isSemverPrefix(target) {
+			return nil, false, fmt.Errorf("%w: ambiguous semantic version %q in range %q", ErrInvalidVersion, target, query)
+		}
+
+		return func(v string) bool { return semver.Compare(v, target) > 0 }, true, nil
+	}
+}
+
+// selectVersion selects the highest (or the lowest) matching release version,
+// or pre-release version if no release version matches.
+// The versions must be sorted.
+func selectVersion(versions []string, match func(v string) bool, preferLower bool) string {
+	var releases, prereleases []string
+
+	for _, v := range versions {
+		if !match(v) {
+			continue
+		}
+
+		if semver.Prerelease(v) == "" {
+			releases = append(releases, v)
+		} else {
+			prereleases = append(prereleases, v)
+		}
+	}
+
+	candidates := releases
+	if len(candidates) == 0 {
+		candidates = prereleases
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return ""
+
+	case preferLower:
+		return candidates[0]
+
+	default:
+		return candidates[len(candidates)-1]
+	}
+}
+
+// isSemverPrefix reports whether the version is a prefix of versions (e.g. "v1", "v1.2").
+func isSemverPrefix(v string) bool {
+	return !strings.ContainsAny(v, "-+") && strings.Count(v, ".") < 2
+}
@@ -0,0 +1,145 @@
+package goproxy
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resolveQuery(t *testing.T) {
+	list := &VersionList{
+		Module: "github.com/ldez/grignotin",
+		Versions: []string{
+			"v0.9.0", "v1.0.0", "v1.0.1", "v1.1.0-rc.1", "v1.1.0", "v1.2.0", "v1.3.0-beta.1", "v1.3.1-rc.1",
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		query    string
+		current  string
+		expected string
+	}{
+		{desc: "latest", query: "latest", expected: "v1.2.0"},
+		{desc: "upgrade without current version", query: "upgrade", expected: "v1.2.0"},
+		{desc: "upgrade from older version", query: "upgrade", current: "v1.0.0", expected: "v1.2.0"},
+		{desc: "upgrade from pre-release", query: "upgrade", current: "v1.3.0-beta.1", expected: "v1.3.0-beta.1"},
+		{desc: "patch", query: "patch", current: "v1.0.0", expected: "v1.0.1"},
+		{desc: "major prefix", query: "v1", expected: "v1.2.0"},
+		{desc: "minor prefix", query: "v1.1", expected: "v1.1.0"},
+		{desc: "pre-release prefix", query: "v1.3", expected: "v1.3.1-rc.1"},
+		{desc: "lower than", query: "<v1.2.0", expected: "v1.1.0"},
+		{desc: "lower than major", query: "<v1", expected: "v0.9.0"},
+		{desc: "lower or equal", query: "<=v1.0.1", expected: "v1.0.1"},
+		{desc: "greater than", query: ">v1.0.0", expected: "v1.0.1"},
+		{desc: "greater or equal", query: ">=v1.1", expected: "v1.1.0"},
+		{desc: "only pre-release", query: ">v1.2.0", expected: "v1.3.0-beta.1"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			version, err := resolveQuery(list, test.query, test.current)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, version)
+		})
+	}
+}
+
+func Test_resolveQuery_errors(t *testing.T) {
+	list := &VersionList{
+		Module:   "github.com/ldez/grignotin",
+		Versions: []string{"v1.0.0"},
+	}
+
+	testCases := []struct {
+		desc    string
+		query   string
+		current string
+		target  error
+	}{
+		{desc: "patch without current version", query: "patch"},
+		{desc: "no matching version", query: ">v1.0.0", target: fs.ErrNotExist},
+		{desc: "no matching prefix", query: "v2", target: fs.ErrNotExist},
+		{desc: "invalid version", query: "<foo", target: ErrInvalidVersion},
+		{desc: "ambiguous lower or equal", query: "<=v1.2", target: ErrInvalidVersion},
+		{desc: "ambiguous greater than", query: ">v1", target: ErrInvalidVersion},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := resolveQuery(list, test.query, test.current)
+			require.Error(t, err)
+
+			if test.target != nil {
+				require.ErrorIs(t, err, test.target)
+			}
+		})
+	}
+}
+
+func TestClient_Query(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(rw, "v1.0.0\nv1.1.0\nv2.0.0+incompatible\n")
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v1.1.0.mod",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(rw, "module github.com/ldez/grignotin\n\nretract v1.1.0\n")
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/{file}",
+		func(rw http.ResponseWriter, req *http.Request) {
+			versions := map[string]string{
+				"v1.0.0.info": "v1.0.0",
+				"v1.1.0.info": "v1.1.0",
+				"main.info":   "v1.1.1-0.20241112194109-818c5a804067",
+			}
+
+			version, ok := versions[req.PathValue("file")]
+			if !ok {
+				http.NotFound(rw, req)
+
+				return
+			}
+
+			_, _ = fmt.Fprintf(rw, `{"Version":%q,"Time":"2024-11-12T19:41:09Z"}`, version)
+		})
+
+	client := NewClient(server.URL)
+
+	testCases := []struct {
+		desc     string
+		query    string
+		expected string
+	}{
+		{desc: "latest without retracted", query: "latest", expected: "v1.0.0"},
+		{desc: "retracted version", query: "v1.1.0", expected: "v1.1.0"},
+		{desc: "branch", query: "main", expected: "v1.1.1-0.20241112194109-818c5a804067"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			info, err := client.Query("github.com/ldez/grignotin", test.query, "")
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, info.Version)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrResponseTooLarge is returned when a response body is larger than [Client.MaxResponseSize].
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// do sends an HTTP request (without body) to a proxy.
+// It adds the default headers, and applies the timeout of the endpoint and the maximum response size.
+// The timeout covers the retries and the reading of the response body.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+
+	if timeout := c.Timeouts[endpointOf(req.URL)]; timeout > 0 {
+		cancel()
+
+		ctx, cancel = context.WithTimeout(req.Context(), timeout)
+	}
+
+	req = req.Clone(ctx)
+
+	for key, values := range c.Header {
+		if req.Header.Get(key) == "" {
+			req.Header[http.CanonicalHeaderKey(key)] = values
+		}
+	}
+
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	if c.MaxResponseSize > 0 && resp.ContentLength > c.MaxResponseSize {
+		_ = resp.Body.Close()
+
+		cancel()
+
+		return nil, fmt.Errorf("%s: %w: %d bytes (max: %d)", req.URL.Redacted(), ErrResponseTooLarge, resp.ContentLength, c.MaxResponseSize)
+	}
+
+	resp.Body = &responseBody{
+		ReadCloser: resp.Body,
+		cancel:     cancel,
+		remaining:  c.MaxResponseSize,
+		limited:    c.MaxResponseSize > 0,
+	}
+
+	return resp, nil
+}
+
+// responseBody limits the size of a response body,
+// and releases the resources of the request context when it is closed.
+type responseBody struct {
+	io.ReadCloser
+
+	cancel    context.CancelFunc
+	remaining int64
+	limited   bool
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	if !b.limited {
+		return b.ReadCloser.Read(p)
+	}
+
+	if b.remaining <= 0 {
+		// Checks if the body is exactly at the limit.
+		var one [1]byte
+
+		n, err := b.ReadCloser.Read(one[:])
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+
+		return 0, err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	err := b.ReadCloser.Close()
+
+	b.cancel()
+
+	return err
+}
+
+// endpointOf returns the kind of resource of a request to a proxy.
+// It returns an empty value if the URL is not a resource of the module proxy protocol.
+func endpointOf(u *url.URL) Endpoint {
+	dir, file := path.Split(u.Path)
+
+	if file == "@latest" {
+		return EndpointLatest
+	}
+
+	if !strings.HasSuffix(dir, "/@v/") {
+		return ""
+	}
+
+	if file == "list" {
+		return EndpointList
+	}
+
+	switch ext := Endpoint(strings.TrimPrefix(path.Ext(file), ".")); ext {
+	case EndpointInfo, EndpointMod, EndpointZip:
+		return ext
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,208 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default values of [RetryPolicy].
+const (
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy configures the retries of the failed HTTP requests:
+// network errors, 429 (Too Many Requests), and 5xx responses (except 501 Not Implemented).
+//
+// The delay between two attempts grows exponentially, with jitter.
+// The Retry-After header of the response is honored.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries.
+	// The zero value disables the retries.
+	MaxRetries int
+
+	// MinBackoff is the delay before the first retry (doubled at each retry).
+	// The default value is [DefaultMinBackoff].
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between two attempts (except when defined by Retry-After).
+	// The default value is [DefaultMaxBackoff].
+	MaxBackoff time.Duration
+}
+
+// backoff returns the delay before the retry (starting at 0).
+// The delay is randomized between half and all of the exponential backoff.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	minBackoff := p.MinBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultMinBackoff
+	}
+
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	d := minBackoff
+	for i := 0; i < retry && d < maxBackoff; i++ {
+		d *= 2
+	}
+
+	d = min(d, maxBackoff)
+
+	return d/2 + rand.N(d/2+1)
+}
+
+// RateLimiter is a token bucket limiting the number of HTTP requests.
+// It must be created with [NewRateLimiter].
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter Creates a new RateLimiter allowing rate requests per second,
+// with bursts of up to burst requests.
+// The rate must be positive.
+func NewRateLimiter(rate float64, burst int) (*RateLimiter, error) {
+	// The negated comparison also rejects NaN.
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		return nil, fmt.Errorf("invalid rate: %v", rate)
+	}
+
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(max(burst, 1)),
+		tokens: float64(max(burst, 1)),
+		last:   time.Now(),
+	}, nil
+}
+
+// Wait blocks until a request is allowed, or the context is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token, and returns the delay before the token is available.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a reserved token.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+// doWithRetry sends an HTTP request (without body), with the rate limiter and the retries.
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for retry := 0; ; retry++ {
+		if c.RateLimiter != nil {
+			err := c.RateLimiter.Wait(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.HTTPClient.Do(req.Clone(ctx))
+
+		if retry >= c.Retry.MaxRetries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := c.Retry.backoff(retry)
+
+		if resp != nil {
+			delay = max(delay, retryAfter(resp.Header.Get("Retry-After")))
+
+			err = handleError(resp)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, errors.Join(ctx.Err(), err)
+		}
+	}
+}
+
+// shouldRetry reports whether the request can be retried:
+// network errors, 429 (Too Many Requests), and 5xx responses (except 501 Not Implemented).
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// retryAfter parses the value of a Retry-After header (delay in seconds or HTTP date).
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
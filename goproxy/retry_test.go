@@ -0,0 +1,227 @@
+package goproxy
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// retryHandler serves the version list after a number of failures.
+func retryHandler(calls *atomic.Int64, failures int64, statusCode int, retryAfter string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) <= failures {
+				if retryAfter != "" {
+					rw.Header().Set("Retry-After", retryAfter)
+				}
+
+				http.Error(rw, http.StatusText(statusCode), statusCode)
+
+				return
+			}
+
+			_, _ = rw.Write([]byte("v0.1.0\n"))
+		})
+
+	return mux
+}
+
+func TestClient_retry(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		failures   int64
+		statusCode int
+		retryAfter string
+		policy     RetryPolicy
+		calls      int64
+		assert     require.ErrorAssertionFunc
+	}{
+		{
+			desc:       "no retries",
+			failures:   1,
+			statusCode: http.StatusServiceUnavailable,
+			calls:      1,
+			assert:     require.Error,
+		},
+		{
+			desc:       "503",
+			failures:   2,
+			statusCode: http.StatusServiceUnavailable,
+			policy:     RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond},
+			calls:      3,
+			assert:     require.NoError,
+		},
+		{
+			desc:       "429 with Retry-After",
+			failures:   1,
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: "0",
+			policy:     RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond},
+			calls:      2,
+			assert:     require.NoError,
+		},
+		{
+			desc:       "too many failures",
+			failures:   5,
+			statusCode: http.StatusBadGateway,
+			policy:     RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond},
+			calls:      3,
+			assert:     require.Error,
+		},
+		{
+			desc:       "not found",
+			failures:   1,
+			statusCode: http.StatusNotFound,
+			policy:     RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond},
+			calls:      1,
+			assert:     require.Error,
+		},
+		{
+			desc:       "not implemented",
+			failures:   1,
+			statusCode: http.StatusNotImplemented,
+			policy:     RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond},
+			calls:      1,
+			assert:     require.Error,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int64
+
+			client := setupServer(t, retryHandler(&calls, test.failures, test.statusCode, test.retryAfter))
+			client.Retry = test.policy
+
+			_, err := client.GetVersions("github.com/ldez/grignotin")
+			test.assert(t, err)
+
+			assert.Equal(t, test.calls, calls.Load())
+		})
+	}
+}
+
+func TestClient_retry_context(t *testing.T) {
+	var calls atomic.Int64
+
+	client := setupServer(t, retryHandler(&calls, 5, http.StatusServiceUnavailable, ""))
+	client.Retry = RetryPolicy{MaxRetries: 3, MinBackoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetVersionsWithContext(ctx, "github.com/ldez/grignotin")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int64(1), calls.Load())
+}
+
+func TestClient_rateLimiter(t *testing.T) {
+	var calls atomic.Int64
+
+	client := setupServer(t, retryHandler(&calls, 0, http.StatusOK, ""))
+
+	limiter, err := NewRateLimiter(50, 1)
+	require.NoError(t, err)
+
+	client.RateLimiter = limiter
+
+	start := time.Now()
+
+	for range 4 {
+		_, err = client.GetVersions("github.com/ldez/grignotin")
+		require.NoError(t, err)
+	}
+
+	// 1 request from the burst, then 3 requests at 50 requests per second.
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, int64(4), calls.Load())
+}
+
+func TestRateLimiter_Wait_canceled(t *testing.T) {
+	limiter, err := NewRateLimiter(0.001, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, limiter.Wait(t.Context()))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	require.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+}
+
+func TestNewRateLimiter_invalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		_, err := NewRateLimiter(rate, 1)
+		require.Error(t, err, rate)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	testCases := []struct {
+		retry int
+		max   time.Duration
+	}{
+		{retry: 0, max: 100 * time.Millisecond},
+		{retry: 1, max: 200 * time.Millisecond},
+		{retry: 2, max: 400 * time.Millisecond},
+		{retry: 10, max: time.Second},
+		{retry: 100, max: time.Second},
+	}
+
+	for _, test := range testCases {
+		d := policy.backoff(test.retry)
+
+		assert.GreaterOrEqual(t, d, test.max/2)
+		assert.LessOrEqual(t, d, test.max)
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:     "seconds",
+			value:    "120",
+			expected: 2 * time.Minute,
+		},
+		{
+			desc:  "date in the past",
+			value: "Wed, 21 Oct 2015 07:28:00 GMT",
+		},
+		{
+			desc:  "invalid",
+			value: "soon",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, retryAfter(test.value))
+		})
+	}
+}
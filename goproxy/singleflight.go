@@ -0,0 +1,87 @@
+package goproxy
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup deduplicates the identical in-flight calls (like golang.org/x/sync/singleflight).
+//
+// The shared call is not canceled by the context of the first caller:
+// it is canceled when all the callers have stopped waiting.
+// The zero value is ready to use.
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+type flightCall[T any] struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	waiters int
+
+	val T
+	err error
+}
+
+// do calls fn once for all the concurrent calls with the same key.
+func (g *flightGroup[T]) do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	g.mu.Lock()
+
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall[T])
+	}
+
+	call, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+		call = &flightCall[T]{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			call.val, call.err = fn(callCtx)
+
+			cancel()
+
+			g.mu.Lock()
+			g.forget(key, call)
+			g.mu.Unlock()
+
+			close(call.done)
+		}()
+	}
+
+	call.waiters++
+
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+
+	case <-ctx.Done():
+		g.mu.Lock()
+
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			g.forget(key, call)
+		}
+
+		g.mu.Unlock()
+
+		var zero T
+
+		return zero, ctx.Err()
+	}
+}
+
+// forget removes the call, if it is still the call of the key.
+// The lock must be held.
+func (g *flightGroup[T]) forget(key string, call *flightCall[T]) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
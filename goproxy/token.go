@@ -0,0 +1,165 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta is how earlier a token is considered expired than its actual expiration time.
+const tokenExpiryDelta = 10 * time.Second
+
+// Token is an access token.
+type Token struct {
+	Value string
+
+	// Expiry is the expiration time of the token.
+	// The zero value means the token never expires.
+	Expiry time.Time
+}
+
+func (t *Token) valid() bool {
+	if t == nil || t.Value == "" {
+		return false
+	}
+
+	return t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry)
+}
+
+// TokenSource returns a token.
+// It is called before the first request and each time the previous token is expired.
+type TokenSource func(ctx context.Context) (*Token, error)
+
+// TokenTransport HTTP transport for API authentication with a token sent inside a header
+// (e.g. "Authorization: Bearer <token>" or "X-JFrog-Art-Api: <token>").
+type TokenTransport struct {
+	header string
+	scheme string
+	source TokenSource
+
+	mu    sync.Mutex
+	token *Token
+
+	AuthScope
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// NewBearerTokenTransport Creates a new TokenTransport that sends "Authorization: Bearer <token>".
+func NewBearerTokenTransport(token string) (*TokenTransport, error) {
+	if token == "" {
+		return nil, errors.New("token missing")
+	}
+
+	return NewTokenSourceTransport("Authorization", "Bearer", staticTokenSource(token))
+}
+
+// NewHeaderTransport Creates a new TokenTransport that sends the token as the value of the header.
+func NewHeaderTransport(header, token string) (*TokenTransport, error) {
+	if token == "" {
+		return nil, errors.New("token missing")
+	}
+
+	return NewTokenSourceTransport(header, "", staticTokenSource(token))
+}
+
+// NewTokenSourceTransport Creates a new TokenTransport with a token obtained lazily from the source.
+// The value of the header is "<scheme> <token>", or "<token>" if the scheme is empty.
+func NewTokenSourceTransport(header, scheme string, source TokenSource) (*TokenTransport, error) {
+	if header == "" {
+		return nil, errors.New("header missing")
+	}
+
+	if source == nil {
+		return nil, errors.New("token source missing")
+	}
+
+	return &TokenTransport{
+		header: http.CanonicalHeaderKey(header),
+		scheme: scheme,
+		source: source,
+	}, nil
+}
+
+// RoundTrip executes a single HTTP transaction.
+func (t *TokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.Allows(req) {
+		return t.transport().RoundTrip(req)
+	}
+
+	token, err := t.getToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	value := token.Value
+	if t.scheme != "" {
+		value = t.scheme + " " + value
+	}
+
+	enrichedReq := cloneRequest(req)
+	enrichedReq.Header.Set(t.header, value)
+
+	return t.transport().RoundTrip(enrichedReq)
+}
+
+// Wrap Wraps an HTTP client Transport with the TokenTransport.
+func (t *TokenTransport) Wrap(client *http.Client) *http.Client {
+	backup := client.Transport
+
+	t.Transport = backup
+	client.Transport = t
+
+	return client
+}
+
+// Client Creates a new HTTP client.
+func (t *TokenTransport) Client() *http.Client {
+	return &http.Client{
+		Transport: t,
+		Timeout:   30 * time.Second,
+	}
+}
+
+func (t *TokenTransport) getToken(ctx context.Context) (*Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token.valid() {
+		return t.token, nil
+	}
+
+	token, err := t.source(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("token source: %w", err)
+	}
+
+	if !token.valid() {
+		return nil, errors.New("token source: invalid or expired token")
+	}
+
+	t.token = token
+
+	return token, nil
+}
+
+func (t *TokenTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+
+	return http.DefaultTransport
+}
+
+func staticTokenSource(value string) TokenSource {
+	token := &Token{Value: value}
+
+	return func(context.Context) (*Token, error) {
+		return token, nil
+	}
+}
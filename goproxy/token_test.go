@@ -0,0 +1,176 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBearerTokenTransport_missing_token(t *testing.T) {
+	transport, err := NewBearerTokenTransport("")
+	require.Error(t, err)
+	assert.Nil(t, transport)
+}
+
+func TestNewHeaderTransport_missing_token(t *testing.T) {
+	transport, err := NewHeaderTransport("X-JFrog-Art-Api", "")
+	require.Error(t, err)
+	assert.Nil(t, transport)
+}
+
+func TestTokenTransport_RoundTrip_static(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		header   string
+		newFunc  func() (*TokenTransport, error)
+		expected string
+	}{
+		{
+			desc:   "bearer",
+			header: "Authorization",
+			newFunc: func() (*TokenTransport, error) {
+				return NewBearerTokenTransport("secret")
+			},
+			expected: "Bearer secret",
+		},
+		{
+			desc:   "header",
+			header: "X-JFrog-Art-Api",
+			newFunc: func() (*TokenTransport, error) {
+				return NewHeaderTransport("x-jfrog-art-api", "secret")
+			},
+			expected: "secret",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = fmt.Fprint(rw, req.Header.Get(test.header))
+			}))
+			t.Cleanup(server.Close)
+
+			transport, err := test.newFunc()
+			require.NoError(t, err)
+
+			transport.Insecure = "127.0.0.1"
+
+			assert.Equal(t, test.expected, doGet(t, transport.Client(), server.URL))
+		})
+	}
+}
+
+func TestTokenTransport_RoundTrip_refresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, req.Header.Get("Authorization"))
+	}))
+	t.Cleanup(server.Close)
+
+	var calls int
+
+	source := func(context.Context) (*Token, error) {
+		calls++
+
+		// The first token expires immediately.
+		if calls == 1 {
+			return &Token{Value: "first", Expiry: time.Now().Add(tokenExpiryDelta + 100*time.Millisecond)}, nil
+		}
+
+		return &Token{Value: "second", Expiry: time.Now().Add(time.Hour)}, nil
+	}
+
+	transport, err := NewTokenSourceTransport("Authorization", "Bearer", source)
+	require.NoError(t, err)
+
+	transport.Insecure = "127.0.0.1"
+
+	client := transport.Client()
+
+	assert.Equal(t, "Bearer first", doGet(t, client, server.URL))
+
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(t, "Bearer second", doGet(t, client, server.URL))
+	assert.Equal(t, "Bearer second", doGet(t, client, server.URL))
+	assert.Equal(t, 2, calls)
+}
+
+func TestTokenTransport_RoundTrip_source_error(t *testing.T) {
+	transport, err := NewTokenSourceTransport("Authorization", "Bearer", func(context.Context) (*Token, error) {
+		return nil, errors.New("boom")
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req) //nolint:bodyclose // the response is nil.
+	require.Error(t, err)
+}
+
+func TestTokenTransport_RoundTrip_redirect(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, req.Header.Get("Authorization"))
+	}))
+	t.Cleanup(target.Close)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, target.URL, http.StatusFound)
+	}))
+	t.Cleanup(origin.Close)
+
+	transport, err := NewBearerTokenTransport("secret")
+	require.NoError(t, err)
+
+	transport.Insecure = "127.0.0.1"
+
+	assert.Empty(t, doGet(t, transport.Client(), origin.URL))
+}
+
+func TestTokenTransport_RoundTrip_hosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, req.Header.Get("Authorization"))
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	transport, err := NewBearerTokenTransport("secret")
+	require.NoError(t, err)
+
+	transport.Insecure = "127.0.0.1"
+	transport.Hosts = []string{"example.com"}
+
+	assert.Empty(t, doGet(t, transport.Client(), server.URL))
+
+	transport.Hosts = []string{u.Hostname()}
+
+	assert.Equal(t, "Bearer secret", doGet(t, transport.Client(), server.URL))
+}
+
+func doGet(t *testing.T, client *http.Client, rawURL string) string {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, rawURL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
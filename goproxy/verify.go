@@ -0,0 +1,290 @@
+package goproxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// ErrChecksumNotFound is returned when the checksum of a module version is unknown.
+var ErrChecksumNotFound = errors.New("checksum not found")
+
+// ChecksumMismatchError is returned when the hash of a downloaded file does not match the expected hash.
+type ChecksumMismatchError struct {
+	Module string
+	// Version is the module version, with the "/go.mod" suffix for the go.mod file.
+	Version  string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s@%s: checksum mismatch: downloaded: %s, expected: %s", e.Module, e.Version, e.Actual, e.Expected)
+}
+
+// SumSource provides the go.sum lines of the module versions (e.g. [GoSum] or a checksum database client).
+type SumSource interface {
+	// Lookup returns the go.sum lines for the module zip and the go.mod file of the module version.
+	Lookup(modulePath, version string) ([]string, error)
+}
+
+// GoSum is the content of a go.sum file.
+type GoSum struct {
+	// lines indexed by "<module> <version>" (the version can have the "/go.mod" suffix).
+	lines map[string][]string
+}
+
+// ParseGoSum parses the content of a go.sum file.
+func ParseGoSum(data []byte) (*GoSum, error) {
+	sum := &GoSum{lines: make(map[string][]string)}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("go.sum: line %d: malformed line", i+1)
+		}
+
+		key := fields[0] + " " + fields[1]
+
+		sum.lines[key] = append(sum.lines[key], strings.Join(fields, " "))
+	}
+
+	return sum, nil
+}
+
+// ReadGoSum reads a go.sum file.
+func ReadGoSum(filename string) (*GoSum, error) {
+	data, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGoSum(data)
+}
+
+// Lookup returns the go.sum lines for the module zip and the go.mod file of the module version.
+func (s *GoSum) Lookup(modulePath, version string) ([]string, error) {
+	// A new slice: the stored lines must not be modified (concurrent lookups).
+	lines := slices.Concat(s.lines[modulePath+" "+version], s.lines[modulePath+" "+version+"/go.mod"])
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%s@%s: %w", modulePath, version, ErrChecksumNotFound)
+	}
+
+	return lines, nil
+}
+
+// GetVerifiedSources gets the contents of the archive file,
+// and checks its hash against the go.sum lines provided by the source.
+func (c *Client) GetVerifiedSources(moduleName, version string, sums SumSource) ([]byte, error) {
+	return c.GetVerifiedSourcesWithContext(context.Background(), moduleName, version, sums)
+}
+
+// GetVerifiedSourcesWithContext gets the contents of the archive file,
+// and checks its hash against the go.sum lines provided by the source.
+func (c *Client) GetVerifiedSourcesWithContext(ctx context.Context, moduleName, version string, sums SumSource) ([]byte, error) {
+	raw, err := c.GetSourcesWithContext(ctx, moduleName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := HashZip(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkHash(sums, moduleName, version, "", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// DownloadVerifiedSources returns an io.ReadCloser that reads the contents of the archive file.
+// The archive is downloaded into a temporary file, and its hash is checked against the go.sum lines provided by the source.
+// It is the caller's responsibility to close the ReadCloser.
+func (c *Client) DownloadVerifiedSources(moduleName, version string, sums SumSource) (io.ReadCloser, error) {
+	return c.DownloadVerifiedSourcesWithContext(context.Background(), moduleName, version, sums)
+}
+
+// DownloadVerifiedSourcesWithContext returns an io.ReadCloser that reads the contents of the archive file.
+// The archive is downloaded into a temporary file, and its hash is checked against the go.sum lines provided by the source.
+// It is the caller's responsibility to close the ReadCloser.
+func (c *Client) DownloadVerifiedSourcesWithContext(ctx context.Context, moduleName, version string, sums SumSource) (io.ReadCloser, error) {
+	file, err := c.downloadToTemp(ctx, moduleName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyZipFile(file, sums, moduleName, version)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// GetVerifiedModFile gets go.mod file,
+// and checks its hash against the go.sum lines provided by the source.
+func (c *Client) GetVerifiedModFile(moduleName, version string, sums SumSource) (*modfile.File, error) {
+	return c.GetVerifiedModFileWithContext(context.Background(), moduleName, version, sums)
+}
+
+// GetVerifiedModFileWithContext gets go.mod file,
+// and checks its hash against the go.sum lines provided by the source.
+func (c *Client) GetVerifiedModFileWithContext(ctx context.Context, moduleName, version string, sums SumSource) (*modfile.File, error) {
+	raw, err := c.GetRawModFileWithContext(ctx, moduleName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := HashMod(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkHash(sums, moduleName, version, "/go.mod", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return modfile.Parse("go.mod", raw, nil)
+}
+
+// HashZip computes the "h1:" hash of a module zip (the hash used inside go.sum files).
+func HashZip(r io.ReaderAt, size int64) (string, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", fmt.Errorf("reading zip: %w", err)
+	}
+
+	files := make([]string, 0, len(z.File))
+	zfiles := make(map[string]*zip.File, len(z.File))
+
+	for _, file := range z.File {
+		files = append(files, file.Name)
+		zfiles[file.Name] = file
+	}
+
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		f := zfiles[name]
+		if f == nil {
+			return nil, fmt.Errorf("file %q not found in zip", name)
+		}
+
+		return f.Open()
+	})
+}
+
+// HashMod computes the "h1:" hash of a go.mod file (the hash used inside go.sum files).
+func HashMod(data []byte) (string, error) {
+	return dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+}
+
+// checkHash checks the hash against the "h1:" hash provided by the source.
+// The suffix is "/go.mod" for a go.mod file, and empty for a module zip.
+func checkHash(sums SumSource, moduleName, version, suffix, hash string) error {
+	lines, err := sums.Lookup(moduleName, version)
+	if err != nil {
+		return err
+	}
+
+	prefix := moduleName + " " + version + suffix + " h1:"
+
+	var expected string
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			expected = strings.TrimPrefix(line, moduleName+" "+version+suffix+" ")
+			break
+		}
+	}
+
+	if expected == "" {
+		return fmt.Errorf("%s@%s%s: %w", moduleName, version, suffix, ErrChecksumNotFound)
+	}
+
+	if expected != hash {
+		return &ChecksumMismatchError{
+			Module:   moduleName,
+			Version:  version + suffix,
+			Expected: expected,
+			Actual:   hash,
+		}
+	}
+
+	return nil
+}
+
+// downloadToTemp downloads the archive file into a temporary file.
+// The file is removed when closed.
+func (c *Client) downloadToTemp(ctx context.Context, moduleName, version string) (*tempFile, error) {
+	body, err := c.DownloadSourcesWithContext(ctx, moduleName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = body.Close() }()
+
+	file, err := os.CreateTemp("", "grignotin-*.zip")
+	if err != nil {
+		return nil, err
+	}
+
+	tmp := &tempFile{File: file}
+
+	_, err = io.Copy(file, body)
+	if err != nil {
+		_ = tmp.Close()
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		_ = tmp.Close()
+		return nil, err
+	}
+
+	return tmp, nil
+}
+
+func verifyZipFile(file *tempFile, sums SumSource, moduleName, version string) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	hash, err := HashZip(file, info.Size())
+	if err != nil {
+		return err
+	}
+
+	return checkHash(sums, moduleName, version, "", hash)
+}
+
+// tempFile is a temporary file removed when closed.
+type tempFile struct {
+	*os.File
+}
+
+// Close closes and removes the file.
+func (f *tempFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.Name()))
+}
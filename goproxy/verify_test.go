@@ -0,0 +1,179 @@
+package goproxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ldez/grignotin/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+const testModFile = `module github.com/ldez/grignotin
+
+go 1.13
+`
+
+// verifyFixture returns a handler serving the module github.com/ldez/grignotin@v0.1.0, and its checksums.
+func verifyFixture(t *testing.T) (http.Handler, *GoSum) {
+	t.Helper()
+
+	archive := testutil.CreateZip(t, map[string]string{
+		"github.com/ldez/grignotin@v0.1.0/go.mod":  testModFile,
+		"github.com/ldez/grignotin@v0.1.0/main.go": "package main\n",
+	})
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.zip",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = rw.Write(archive)
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.mod",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(rw, testModFile)
+		})
+
+	zipFile := filepath.Join(t.TempDir(), "v0.1.0.zip")
+	require.NoError(t, os.WriteFile(zipFile, archive, 0o600))
+
+	zipHash, err := dirhash.HashZip(zipFile, dirhash.Hash1)
+	require.NoError(t, err)
+
+	modHash, err := HashMod([]byte(testModFile))
+	require.NoError(t, err)
+
+	sums, err := ParseGoSum(fmt.Appendf(nil, "github.com/ldez/grignotin v0.1.0 %s\ngithub.com/ldez/grignotin v0.1.0/go.mod %s\n", zipHash, modHash))
+	require.NoError(t, err)
+
+	return mux, sums
+}
+
+func TestClient_GetVerifiedSources(t *testing.T) {
+	handler, sums := verifyFixture(t)
+
+	client := setupServer(t, handler)
+
+	raw, err := client.GetVerifiedSources("github.com/ldez/grignotin", "v0.1.0", sums)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, raw)
+}
+
+func TestClient_GetVerifiedSources_mismatch(t *testing.T) {
+	handler, _ := verifyFixture(t)
+
+	client := setupServer(t, handler)
+
+	sums, err := ParseGoSum([]byte("github.com/ldez/grignotin v0.1.0 h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n"))
+	require.NoError(t, err)
+
+	_, err = client.GetVerifiedSources("github.com/ldez/grignotin", "v0.1.0", sums)
+
+	var mismatch *ChecksumMismatchError
+	require.ErrorAs(t, err, &mismatch)
+
+	assert.Equal(t, "github.com/ldez/grignotin", mismatch.Module)
+	assert.Equal(t, "v0.1.0", mismatch.Version)
+	assert.Equal(t, "h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", mismatch.Expected)
+}
+
+func TestClient_GetVerifiedSources_not_found(t *testing.T) {
+	handler, _ := verifyFixture(t)
+
+	client := setupServer(t, handler)
+
+	sums, err := ParseGoSum(nil)
+	require.NoError(t, err)
+
+	_, err = client.GetVerifiedSources("github.com/ldez/grignotin", "v0.1.0", sums)
+	require.ErrorIs(t, err, ErrChecksumNotFound)
+}
+
+func TestClient_DownloadVerifiedSources(t *testing.T) {
+	handler, sums := verifyFixture(t)
+
+	client := setupServer(t, handler)
+
+	reader, err := client.DownloadVerifiedSources("github.com/ldez/grignotin", "v0.1.0", sums)
+	require.NoError(t, err)
+
+	raw, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, raw)
+
+	name := reader.(*tempFile).Name()
+
+	require.NoError(t, reader.Close())
+
+	assert.NoFileExists(t, name)
+}
+
+func TestClient_GetVerifiedModFile(t *testing.T) {
+	handler, sums := verifyFixture(t)
+
+	client := setupServer(t, handler)
+
+	file, err := client.GetVerifiedModFile("github.com/ldez/grignotin", "v0.1.0", sums)
+	require.NoError(t, err)
+
+	assert.Equal(t, "github.com/ldez/grignotin", file.Module.Mod.Path)
+}
+
+func TestClient_GetVerifiedModFile_mismatch(t *testing.T) {
+	handler, _ := verifyFixture(t)
+
+	client := setupServer(t, handler)
+
+	hash, err := HashMod([]byte("module other\n"))
+	require.NoError(t, err)
+
+	sums, err := ParseGoSum([]byte("github.com/ldez/grignotin v0.1.0/go.mod " + hash + "\n"))
+	require.NoError(t, err)
+
+	_, err = client.GetVerifiedModFile("github.com/ldez/grignotin", "v0.1.0", sums)
+
+	var mismatch *ChecksumMismatchError
+	require.ErrorAs(t, err, &mismatch)
+
+	assert.Equal(t, "v0.1.0/go.mod", mismatch.Version)
+}
+
+func TestParseGoSum_error(t *testing.T) {
+	_, err := ParseGoSum([]byte("github.com/ldez/grignotin v0.1.0\n"))
+	require.Error(t, err)
+}
+
+func TestGoSum_Lookup(t *testing.T) {
+	sums, err := ParseGoSum([]byte(`github.com/ldez/grignotin v0.1.0 h1:a=
+github.com/ldez/grignotin v0.1.0 h1:b=
+github.com/ldez/grignotin v0.1.0 h1:c=
+github.com/ldez/grignotin v0.1.0/go.mod h1:d=
+`))
+	require.NoError(t, err)
+
+	lines, err := sums.Lookup("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	require.Len(t, lines, 4)
+
+	// The result does not share the lines of the go.sum.
+	assert.NotSame(t, &sums.lines["github.com/ldez/grignotin v0.1.0"][0], &lines[0])
+}
+
+func TestReadGoSum(t *testing.T) {
+	sums, err := ReadGoSum(filepath.Join("..", "go.sum"))
+	require.NoError(t, err)
+
+	lines, err := sums.Lookup("github.com/stretchr/testify", "v1.11.1")
+	require.NoError(t, err)
+
+	assert.Len(t, lines, 2)
+}
@@ -0,0 +1,227 @@
+package goproxy
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/ldez/grignotin/internal/modver"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// VersionListOptions configures [Client.GetVersionList].
+type VersionListOptions struct {
+	// IncludeRetracted keeps the versions retracted by the go.mod file of the latest version.
+	IncludeRetracted bool
+
+	// IncludeIncompatible keeps the "+incompatible" versions.
+	IncludeIncompatible bool
+}
+
+// Retraction is a "retract" directive of a go.mod file.
+type Retraction struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+// contains reports whether the version is in the retracted interval.
+func (r Retraction) contains(version string) bool {
+	return semver.Compare(r.Low, version) <= 0 && semver.Compare(version, r.High) <= 0
+}
+
+// VersionList is the list of the versions of a module, sorted by semantic version.
+type VersionList struct {
+	Module string
+
+	// Versions are the release and pre-release versions, in ascending order.
+	// The invalid versions and the pseudo-versions are excluded.
+	Versions []string
+
+	// PseudoVersions are the pseudo-versions, in ascending order.
+	// If the proxy does not list any version, it contains the version returned by the "@latest" endpoint.
+	PseudoVersions []string
+
+	// Retractions are the "retract" directives of the go.mod file of the latest version.
+	Retractions []Retraction
+}
+
+// Releases returns the release versions, in ascending order.
+func (l *VersionList) Releases() []string {
+	return slices.DeleteFunc(slices.Clone(l.Versions), func(v string) bool {
+		return semver.Prerelease(v) != ""
+	})
+}
+
+// Prereleases returns the pre-release versions, in ascending order.
+func (l *VersionList) Prereleases() []string {
+	return slices.DeleteFunc(slices.Clone(l.Versions), func(v string) bool {
+		return semver.Prerelease(v) == ""
+	})
+}
+
+// IsRetracted reports whether the version is retracted.
+func (l *VersionList) IsRetracted(version string) bool {
+	return slices.ContainsFunc(l.Retractions, func(r Retraction) bool {
+		return r.contains(version)
+	})
+}
+
+// Latest returns the version selected by the "@latest" query of "go get":
+// the highest release version, or the highest pre-release version if there is no release version,
+// or the highest pseudo-version if there is no tagged version.
+// It returns an empty string if there is no version.
+func (l *VersionList) Latest() string {
+	return latestOf(l.Versions, l.PseudoVersions)
+}
+
+// Upgrade returns the version selected by the "@upgrade" query of "go get":
+// like [VersionList.Latest], but the current version is kept if it is higher (e.g. a pre-release or a pseudo-version).
+// The current version can be empty.
+func (l *VersionList) Upgrade(current string) string {
+	return keepHigher(current, l.Latest())
+}
+
+// Patch returns the version selected by the "@patch" query of "go get":
+// the latest version with the same major and minor version as the current version,
+// but the current version is kept if it is higher.
+// It returns an empty string if the current version is not a valid version.
+func (l *VersionList) Patch(current string) string {
+	if !semver.IsValid(current) {
+		return ""
+	}
+
+	prefix := semver.MajorMinor(current) + "."
+
+	versions := slices.DeleteFunc(slices.Clone(l.Versions), func(v string) bool {
+		return !strings.HasPrefix(v, prefix)
+	})
+
+	return keepHigher(current, latestOf(versions, nil))
+}
+
+// GetVersionList gets the versions of a module, sorted by semantic version.
+//
+// The retractions are read from the go.mod file of the latest version.
+func (c *Client) GetVersionList(moduleName string, opts VersionListOptions) (*VersionList, error) {
+	return c.GetVersionListWithContext(context.Background(), moduleName, opts)
+}
+
+// GetVersionListWithContext gets the versions of a module, sorted by semantic version.
+//
+// The retractions are read from the go.mod file of the latest version.
+func (c *Client) GetVersionListWithContext(ctx context.Context, moduleName string, opts VersionListOptions) (*VersionList, error) {
+	raw, err := c.GetVersionsWithContext(ctx, moduleName)
+	if err != nil {
+		return nil, err
+	}
+
+	list := newVersionList(moduleName, raw, opts.IncludeIncompatible)
+
+	if len(list.Versions) == 0 && len(list.PseudoVersions) == 0 {
+		info, errLatest := c.GetLatestWithContext(ctx, moduleName)
+		if errLatest != nil && !errors.Is(errLatest, fs.ErrNotExist) {
+			return nil, errLatest
+		}
+
+		if info != nil && semver.IsValid(info.Version) {
+			list.PseudoVersions = append(list.PseudoVersions, info.Version)
+		}
+	}
+
+	// The go.mod files of the "+incompatible" versions are synthesized: they cannot contain retractions.
+	latest := latestOf(slices.DeleteFunc(slices.Clone(list.Versions), isIncompatible), list.PseudoVersions)
+	if latest == "" {
+		return list, nil
+	}
+
+	list.Retractions, err = c.getRetractions(ctx, moduleName, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.IncludeRetracted {
+		list.Versions = slices.DeleteFunc(list.Versions, list.IsRetracted)
+		list.PseudoVersions = slices.DeleteFunc(list.PseudoVersions, list.IsRetracted)
+	}
+
+	return list, nil
+}
+
+func (c *Client) getRetractions(ctx context.Context, moduleName, version string) ([]Retraction, error) {
+	file, err := c.GetModFileWithContext(ctx, moduleName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var retractions []Retraction
+
+	for _, r := range file.Retract {
+		retractions = append(retractions, Retraction{
+			Low:       r.Low,
+			High:      r.High,
+			Rationale: r.Rationale,
+		})
+	}
+
+	return retractions, nil
+}
+
+// newVersionList sorts and splits the versions returned by a proxy.
+func newVersionList(moduleName string, raw []string, includeIncompatible bool) *VersionList {
+	list := &VersionList{Module: moduleName}
+
+	for _, v := range raw {
+		v = strings.TrimSpace(v)
+
+		switch {
+		case !semver.IsValid(v):
+			continue
+
+		case !includeIncompatible && isIncompatible(v):
+			continue
+
+		case module.IsPseudoVersion(v):
+			list.PseudoVersions = append(list.PseudoVersions, v)
+
+		default:
+			list.Versions = append(list.Versions, v)
+		}
+	}
+
+	list.Versions = sortVersions(list.Versions)
+	list.PseudoVersions = sortVersions(list.PseudoVersions)
+
+	return list
+}
+
+func sortVersions(versions []string) []string {
+	slices.SortFunc(versions, semver.Compare)
+
+	return slices.CompactFunc(versions, func(a, b string) bool {
+		return semver.Compare(a, b) == 0
+	})
+}
+
+func isIncompatible(version string) bool {
+	return semver.Build(version) == "+incompatible"
+}
+
+// latestOf returns the highest release version, or the highest pre-release version,
+// or the highest pseudo-version.
+func latestOf(versions, pseudoVersions []string) string {
+	return cmp.Or(modver.Latest(versions), modver.Latest(pseudoVersions))
+}
+
+// keepHigher returns the current version if it is higher than the candidate.
+func keepHigher(current, candidate string) string {
+	if semver.IsValid(current) && semver.Compare(current, candidate) > 0 {
+		return current
+	}
+
+	return candidate
+}
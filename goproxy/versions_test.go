@@ -0,0 +1,194 @@
+package goproxy
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRetractModFile = `module github.com/ldez/grignotin
+
+go 1.22
+
+retract (
+	v1.1.0 // Broken build.
+	[v0.9.0, v0.9.5]
+)
+`
+
+func TestClient_GetVersionList(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		list     string
+		opts     VersionListOptions
+		expected *VersionList
+	}{
+		{
+			desc: "sorted without retracted",
+			list: "v1.0.0\nv0.9.1\nv1.1.0\nv0.10.0\ninvalid\nv1.0.0\nv2.0.0+incompatible\nv1.2.0-rc.1\n",
+			expected: &VersionList{
+				Module:   "github.com/ldez/grignotin",
+				Versions: []string{"v0.10.0", "v1.0.0", "v1.2.0-rc.1"},
+				Retractions: []Retraction{
+					{Low: "v1.1.0", High: "v1.1.0", Rationale: "Broken build."},
+					{Low: "v0.9.0", High: "v0.9.5"},
+				},
+			},
+		},
+		{
+			desc: "include retracted and incompatible",
+			list: "v1.0.0\nv0.9.1\nv1.1.0\nv2.0.0+incompatible\n",
+			opts: VersionListOptions{IncludeRetracted: true, IncludeIncompatible: true},
+			expected: &VersionList{
+				Module:   "github.com/ldez/grignotin",
+				Versions: []string{"v0.9.1", "v1.0.0", "v1.1.0", "v2.0.0+incompatible"},
+				Retractions: []Retraction{
+					{Low: "v1.1.0", High: "v1.1.0", Rationale: "Broken build."},
+					{Low: "v0.9.0", High: "v0.9.5"},
+				},
+			},
+		},
+		{
+			desc: "pseudo-versions from @latest",
+			expected: &VersionList{
+				Module:         "github.com/ldez/grignotin",
+				PseudoVersions: []string{"v0.0.0-20241112194109-818c5a804067"},
+				Retractions: []Retraction{
+					{Low: "v1.1.0", High: "v1.1.0", Rationale: "Broken build."},
+					{Low: "v0.9.0", High: "v0.9.5"},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var modCalls atomic.Int64
+
+			mux := http.NewServeMux()
+
+			mux.HandleFunc("GET /github.com/ldez/grignotin/@v/list",
+				func(rw http.ResponseWriter, _ *http.Request) {
+					_, _ = fmt.Fprint(rw, test.list)
+				})
+
+			mux.HandleFunc("GET /github.com/ldez/grignotin/@v/{file}",
+				func(rw http.ResponseWriter, req *http.Request) {
+					switch req.PathValue("file") {
+					case "v1.2.0-rc.1.mod", "v1.1.0.mod", "v0.0.0-20241112194109-818c5a804067.mod":
+						modCalls.Add(1)
+
+						_, _ = fmt.Fprint(rw, testRetractModFile)
+
+					default:
+						http.NotFound(rw, req)
+					}
+				})
+
+			mux.HandleFunc("GET /github.com/ldez/grignotin/@latest",
+				func(rw http.ResponseWriter, _ *http.Request) {
+					_, _ = fmt.Fprint(rw, `{"Version":"v0.0.0-20241112194109-818c5a804067","Time":"2024-11-12T19:41:09Z"}`)
+				})
+
+			client := setupServer(t, mux)
+
+			list, err := client.GetVersionList("github.com/ldez/grignotin", test.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, list)
+			assert.Equal(t, int64(1), modCalls.Load())
+		})
+	}
+}
+
+func TestVersionList_queries(t *testing.T) {
+	list := &VersionList{
+		Versions: []string{"v0.9.0", "v1.0.0", "v1.0.1", "v1.1.0", "v1.2.0-rc.1"},
+	}
+
+	assert.Equal(t, []string{"v0.9.0", "v1.0.0", "v1.0.1", "v1.1.0"}, list.Releases())
+	assert.Equal(t, []string{"v1.2.0-rc.1"}, list.Prereleases())
+
+	testCases := []struct {
+		desc            string
+		current         string
+		expectedUpgrade string
+		expectedPatch   string
+	}{
+		{
+			desc:            "no current version",
+			expectedUpgrade: "v1.1.0",
+		},
+		{
+			desc:            "older version",
+			current:         "v1.0.0",
+			expectedUpgrade: "v1.1.0",
+			expectedPatch:   "v1.0.1",
+		},
+		{
+			desc:            "pre-release",
+			current:         "v1.2.0-rc.1",
+			expectedUpgrade: "v1.2.0-rc.1",
+			expectedPatch:   "v1.2.0-rc.1",
+		},
+		{
+			desc:            "pseudo-version",
+			current:         "v1.1.1-0.20241112194109-818c5a804067",
+			expectedUpgrade: "v1.1.1-0.20241112194109-818c5a804067",
+			expectedPatch:   "v1.1.1-0.20241112194109-818c5a804067",
+		},
+		{
+			desc:            "unknown minor version",
+			current:         "v0.8.0",
+			expectedUpgrade: "v1.1.0",
+			expectedPatch:   "v0.8.0",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, "v1.1.0", list.Latest())
+			assert.Equal(t, test.expectedUpgrade, list.Upgrade(test.current))
+			assert.Equal(t, test.expectedPatch, list.Patch(test.current))
+		})
+	}
+}
+
+func TestVersionList_Latest(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		list     *VersionList
+		expected string
+	}{
+		{
+			desc: "empty",
+			list: &VersionList{},
+		},
+		{
+			desc:     "only pre-releases",
+			list:     &VersionList{Versions: []string{"v1.0.0-alpha", "v1.0.0-beta"}},
+			expected: "v1.0.0-beta",
+		},
+		{
+			desc:     "only pseudo-versions",
+			list:     &VersionList{PseudoVersions: []string{"v0.0.0-20241112194109-818c5a804067"}},
+			expected: "v0.0.0-20241112194109-818c5a804067",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, test.list.Latest())
+		})
+	}
+}
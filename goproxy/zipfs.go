@@ -0,0 +1,297 @@
+package goproxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// zipReadAhead is the minimum size of a range request.
+// The central directory of a zip is at the end of the file, so the first request reads the end of the zip.
+const zipReadAhead = 64 << 10
+
+// OpenSources returns a read-only file system backed by the archive file.
+// See [Client.OpenSourcesWithContext].
+func (c *Client) OpenSources(moduleName, version string) (fs.FS, error) {
+	return c.OpenSourcesWithContext(context.Background(), moduleName, version)
+}
+
+// OpenSourcesWithContext returns a read-only file system backed by the archive file.
+//
+// When the proxy supports HTTP range requests, only the central directory of the zip
+// and the content of the opened files are downloaded.
+// Otherwise, the archive file is entirely downloaded into memory.
+//
+// The context is also used by the reads of the files.
+// The "<module>@<version>/" prefix is removed.
+func (c *Client) OpenSourcesWithContext(ctx context.Context, moduleName, version string) (fs.FS, error) {
+	endpoint, err := versionEndpoint(moduleName, version, ".zip")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		r    io.ReaderAt
+		size int64
+	)
+
+	err = c.walkProxies(moduleName, func(proxy proxySpec) error {
+		var err error
+
+		r, size, err = c.openZipFrom(ctx, proxy, moduleName, endpoint)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%s@%s: reading zip: %w", moduleName, version, err)
+	}
+
+	return fs.Sub(zr, moduleName+"@"+version)
+}
+
+// openZipFrom returns a reader of the archive file of a proxy.
+func (c *Client) openZipFrom(ctx context.Context, proxy proxySpec, moduleName, endpoint string) (io.ReaderAt, int64, error) {
+	if proxy.url == nil || (proxy.url.Scheme != "http" && proxy.url.Scheme != "https") {
+		body, err := c.fetchFrom(ctx, proxy, moduleName, endpoint)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		defer func() { _ = body.Close() }()
+
+		return readAllAt(body)
+	}
+
+	u, err := escapedURL(proxy.url, moduleName, endpoint)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r, size, err := c.openHTTPZip(ctx, u)
+
+	return r, size, withModule(err, moduleName, endpoint)
+}
+
+// openHTTPZip reads the end of the archive file with a range request.
+// If the server ignores the range, the archive file is entirely read.
+func (c *Client) openHTTPZip(ctx context.Context, endpoint *url.URL) (io.ReaderAt, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req.Header.Set("Range", "bytes=-"+strconv.Itoa(zipReadAhead))
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return nil, 0, handleError(resp)
+	}
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return readAllAt(resp.Body)
+	}
+
+	start, _, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if c.MaxResponseSize > 0 && total > c.MaxResponseSize {
+		return nil, 0, fmt.Errorf("%s: %w: %d bytes (max: %d)", endpoint.Redacted(), ErrResponseTooLarge, total, c.MaxResponseSize)
+	}
+
+	tail, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if start+int64(len(tail)) != total {
+		return nil, 0, fmt.Errorf("unexpected partial content: %d bytes at %d (size: %d)", len(tail), start, total)
+	}
+
+	r := &httpReaderAt{
+		ctx:  ctx,
+		do:   c.do,
+		url:  endpoint.String(),
+		size: total,
+		tail: byteRange{off: start, data: tail},
+	}
+
+	return r, total, nil
+}
+
+// readAllAt reads all the content into memory.
+func readAllAt(body io.Reader) (io.ReaderAt, int64, error) {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return bytes.NewReader(raw), int64(len(raw)), nil
+}
+
+// byteRange is a part of a remote file.
+type byteRange struct {
+	off  int64
+	data []byte
+}
+
+func (b byteRange) contains(off int64) bool {
+	return off >= b.off && off < b.off+int64(len(b.data))
+}
+
+// httpReaderAt reads a remote file with HTTP range requests.
+// The end of the file (central directory of a zip) and the last requested range are kept in memory.
+type httpReaderAt struct {
+	ctx  context.Context
+	do   func(req *http.Request) (*http.Response, error)
+	url  string
+	size int64
+
+	mu   sync.Mutex
+	tail byteRange
+	last byteRange
+}
+
+// ReadAt implements [io.ReaderAt].
+func (r *httpReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	var n int
+
+	for n < len(p) && off+int64(n) < r.size {
+		b, err := r.rangeAt(off+int64(n), len(p)-n)
+		if err != nil {
+			return n, err
+		}
+
+		n += copy(p[n:], b.data[off+int64(n)-b.off:])
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// rangeAt returns a range containing the offset, with a read-ahead.
+func (r *httpReaderAt) rangeAt(off int64, length int) (byteRange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case r.tail.contains(off):
+		return r.tail, nil
+	case r.last.contains(off):
+		return r.last, nil
+	}
+
+	end := min(off+int64(max(length, zipReadAhead)), r.tail.off, r.size) - 1
+
+	data, err := r.fetchRange(off, end)
+	if err != nil {
+		return byteRange{}, err
+	}
+
+	r.last = byteRange{off: off, data: data}
+
+	return r.last, nil
+}
+
+// fetchRange fetches the bytes between start and end (inclusive).
+func (r *httpReaderAt) fetchRange(start, end int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := r.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		if resp.StatusCode/100 != 2 {
+			return nil, handleError(resp)
+		}
+
+		return nil, fmt.Errorf("range request not honored: %s", resp.Status)
+	}
+
+	gotStart, gotEnd, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, err
+	}
+
+	if gotStart != start || gotEnd != end || total != r.size {
+		return nil, fmt.Errorf("unexpected content range: %s", resp.Header.Get("Content-Range"))
+	}
+
+	data := make([]byte, end-start+1)
+
+	_, err = io.ReadFull(resp.Body, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return data, nil
+}
+
+// parseContentRange parses a Content-Range header ("bytes <start>-<end>/<size>").
+func parseContentRange(value string) (start, end, size int64, err error) {
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid content range: %q", value)
+	}
+
+	rng, total, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid content range: %q", value)
+	}
+
+	first, last, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid content range: %q", value)
+	}
+
+	start, err1 := strconv.ParseInt(first, 10, 64)
+	end, err2 := strconv.ParseInt(last, 10, 64)
+	size, err3 := strconv.ParseInt(total, 10, 64)
+
+	if errors.Join(err1, err2, err3) != nil || start > end || end >= size {
+		return 0, 0, 0, fmt.Errorf("invalid content range: %q", value)
+	}
+
+	return start, end, size, nil
+}
@@ -0,0 +1,170 @@
+package goproxy
+
+import (
+	"bytes"
+	"io/fs"
+	"math/rand/v2"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ldez/grignotin/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_OpenSources(t *testing.T) {
+	// Random data are not compressible.
+	large := make([]byte, 1<<20)
+	for i := range large {
+		large[i] = byte(rand.IntN(256))
+	}
+
+	archive := testutil.CreateZip(t, map[string]string{
+		"github.com/ldez/grignotin@v0.1.0/go.mod":     testModFile,
+		"github.com/ldez/grignotin@v0.1.0/LICENSE":    "MIT\n",
+		"github.com/ldez/grignotin@v0.1.0/foo/foo.go": "package foo\n",
+		"github.com/ldez/grignotin@v0.1.0/large.bin":  string(large),
+	})
+
+	testCases := []struct {
+		desc   string
+		ranges bool
+		cache  Cache
+		assert func(t *testing.T, served int64)
+	}{
+		{
+			desc:   "range requests",
+			ranges: true,
+			assert: func(t *testing.T, served int64) {
+				t.Helper()
+
+				assert.Less(t, served, int64(len(archive)/4))
+			},
+		},
+		{
+			desc:   "range requests with a cache",
+			ranges: true,
+			cache:  NewMemoryCache(1 << 20),
+			assert: func(t *testing.T, served int64) {
+				t.Helper()
+
+				assert.Less(t, served, int64(len(archive)/4))
+			},
+		},
+		{
+			desc: "full download",
+			assert: func(t *testing.T, served int64) {
+				t.Helper()
+
+				assert.Equal(t, int64(len(archive)), served)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var served atomic.Int64
+
+			mux := http.NewServeMux()
+
+			mux.HandleFunc("GET /github.com/ldez/grignotin/@v/v0.1.0.zip",
+				func(rw http.ResponseWriter, req *http.Request) {
+					cw := &countingWriter{ResponseWriter: rw, count: &served}
+
+					if !test.ranges {
+						_, _ = cw.Write(archive)
+						return
+					}
+
+					http.ServeContent(cw, req, "v0.1.0.zip", time.Time{}, bytes.NewReader(archive))
+				})
+
+			client := setupServer(t, mux)
+			client.Cache = test.cache
+
+			fsys, err := client.OpenSources("github.com/ldez/grignotin", "v0.1.0")
+			require.NoError(t, err)
+
+			content, err := fs.ReadFile(fsys, "LICENSE")
+			require.NoError(t, err)
+
+			assert.Equal(t, "MIT\n", string(content))
+
+			content, err = fs.ReadFile(fsys, "foo/foo.go")
+			require.NoError(t, err)
+
+			assert.Equal(t, "package foo\n", string(content))
+
+			test.assert(t, served.Load())
+
+			content, err = fs.ReadFile(fsys, "large.bin")
+			require.NoError(t, err)
+
+			assert.Equal(t, large, content)
+		})
+	}
+}
+
+func TestClient_OpenSources_notFound(t *testing.T) {
+	client := setupServer(t, http.NotFoundHandler())
+
+	_, err := client.OpenSources("github.com/ldez/grignotin", "v0.2.0")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func Test_parseContentRange(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected [3]int64
+		assert   require.ErrorAssertionFunc
+	}{
+		{
+			desc:     "valid",
+			value:    "bytes 10-19/100",
+			expected: [3]int64{10, 19, 100},
+			assert:   require.NoError,
+		},
+		{
+			desc:   "unknown size",
+			value:  "bytes 10-19/*",
+			assert: require.Error,
+		},
+		{
+			desc:   "out of bounds",
+			value:  "bytes 10-100/100",
+			assert: require.Error,
+		},
+		{
+			desc:   "unsatisfied",
+			value:  "bytes */100",
+			assert: require.Error,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			start, end, size, err := parseContentRange(test.value)
+			test.assert(t, err)
+
+			assert.Equal(t, test.expected, [3]int64{start, end, size})
+		})
+	}
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+
+	count *atomic.Int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.count.Add(int64(n))
+
+	return n, err
+}
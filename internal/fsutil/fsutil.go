@@ -0,0 +1,48 @@
+// Package fsutil File system helpers shared by the packages of the module.
+package fsutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes the content of the reader into the file atomically:
+// the content is written into a temporary file (in the same directory), then the temporary file is renamed.
+// The parent directories are created if needed.
+func WriteFile(name string, r io.Reader) error {
+	dir := filepath.Dir(name)
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(name)+".tmp-")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), name)
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	name := filepath.Join(dir, "a", "b", "file.txt")
+
+	err := WriteFile(name, strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	err = WriteFile(name, strings.NewReader("world"))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(name)
+	require.NoError(t, err)
+
+	assert.Equal(t, "world", string(content))
+
+	// No temporary file is left.
+	entries, err := os.ReadDir(filepath.Dir(name))
+	require.NoError(t, err)
+
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,41 @@
+// Package modver Helpers about the module versions shared by the packages of the module.
+package modver
+
+import (
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Latest returns the highest release version,
+// or the highest pre-release version if there is no release version.
+// The invalid versions are ignored, and the versions don't need to be sorted.
+func Latest(versions []string) string {
+	var latest, latestPrerelease string
+
+	for _, v := range versions {
+		if !semver.IsValid(v) {
+			continue
+		}
+
+		if semver.Prerelease(v) == "" {
+			if semver.Compare(v, latest) > 0 {
+				latest = v
+			}
+		} else if semver.Compare(v, latestPrerelease) > 0 {
+			latestPrerelease = v
+		}
+	}
+
+	if latest == "" {
+		return latestPrerelease
+	}
+
+	return latest
+}
+
+// IsCanonical reports whether the version is a canonical module version
+// (e.g. "v1.2.3" or "v2.0.0+incompatible", but not "v1.2" or "master").
+// The content of a canonical version is immutable: the other versions are queries that can resolve to another version later.
+func IsCanonical(v string) bool {
+	return semver.IsValid(v) && module.CanonicalVersion(v) == v
+}
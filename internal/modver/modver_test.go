@@ -0,0 +1,61 @@
+package modver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatest(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		versions []string
+		expected string
+	}{
+		{
+			desc:     "release",
+			versions: []string{"v1.0.0", "v1.10.0", "v1.2.0", "v2.0.0-rc.1"},
+			expected: "v1.10.0",
+		},
+		{
+			desc:     "only pre-releases",
+			versions: []string{"v1.0.0-alpha", "v1.0.0-beta"},
+			expected: "v1.0.0-beta",
+		},
+		{
+			desc:     "invalid versions",
+			versions: []string{"master", "v1.0.0", "1.2.0"},
+			expected: "v1.0.0",
+		},
+		{
+			desc: "empty",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, Latest(test.versions))
+		})
+	}
+}
+
+func TestIsCanonical(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected bool
+	}{
+		{version: "v1.2.3", expected: true},
+		{version: "v1.2.3-rc.1", expected: true},
+		{version: "v0.0.0-20200101000000-abcdefabcdef", expected: true},
+		{version: "v2.0.0+incompatible", expected: true},
+		{version: "v1.2.3+meta"},
+		{version: "v1.2"},
+		{version: "master"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, IsCanonical(test.version), test.version)
+	}
+}
@@ -0,0 +1,138 @@
+// Package proxylist Parser of the GOPROXY lists shared by the packages of the module.
+package proxylist
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Special elements of a GOPROXY list.
+const (
+	// Off disallows downloading from any source.
+	Off = "off"
+	// Direct downloads directly from version control repositories.
+	Direct = "direct"
+)
+
+// Proxy is an element of a GOPROXY list.
+type Proxy struct {
+	// Name is "off", "direct", or the URL of the proxy.
+	Name string
+	URL  *url.URL
+
+	// FallBackOnError is true if the next proxy must be tried on any error (separator "|"),
+	// otherwise only on 404 and 410 responses (separator ",").
+	FallBackOnError bool
+}
+
+// Parse parses a GOPROXY value.
+// https://go.dev/ref/mod#goproxy-protocol
+func Parse(goproxy string) ([]Proxy, error) {
+	var proxies []Proxy
+
+	for goproxy != "" {
+		var rawURL string
+
+		fallBackOnError := false
+
+		if i := strings.IndexAny(goproxy, ",|"); i >= 0 {
+			rawURL = goproxy[:i]
+			fallBackOnError = goproxy[i] == '|'
+			goproxy = goproxy[i+1:]
+		} else {
+			rawURL = goproxy
+			goproxy = ""
+		}
+
+		rawURL = strings.TrimSpace(rawURL)
+		if rawURL == "" {
+			continue
+		}
+
+		if rawURL == Off {
+			// "off" always fails hard, so the next elements are never used.
+			proxies = append(proxies, Proxy{Name: Off})
+			break
+		}
+
+		if rawURL == Direct {
+			// Like the go command, "direct" is the end of the line.
+			proxies = append(proxies, Proxy{Name: Direct})
+			break
+		}
+
+		// Single-word tokens are reserved for built-in behaviors,
+		// and anything containing ":/" or matching an absolute file path must be a complete URL.
+		// For all other paths, implicitly add "https://".
+		if strings.ContainsAny(rawURL, ".:/") && !strings.Contains(rawURL, ":/") && !filepath.IsAbs(rawURL) && !path.IsAbs(rawURL) {
+			rawURL = "https://" + rawURL
+		}
+
+		u, err := ParseURL(rawURL)
+		if err != nil {
+			return nil, err
+		}
+
+		proxies = append(proxies, Proxy{Name: rawURL, URL: u, FallBackOnError: fallBackOnError})
+	}
+
+	if len(proxies) == 0 {
+		return nil, errors.New("GOPROXY list is not the empty string, but contains no entries")
+	}
+
+	return proxies, nil
+}
+
+// ParseURL parses the URL of a proxy (http, https, or file URL).
+func ParseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return u, nil
+
+	case "file":
+		_, err = FileURLToPath(u)
+		if err != nil {
+			return nil, fmt.Errorf("proxy URL %q: %w", rawURL, err)
+		}
+
+		return u, nil
+
+	case "":
+		return nil, fmt.Errorf("proxy URL %q: missing scheme", rawURL)
+
+	default:
+		return nil, fmt.Errorf("proxy URL %q: invalid scheme (must be https, http, or file)", rawURL)
+	}
+}
+
+// FileURLToPath returns the local path of a file:// URL.
+func FileURLToPath(u *url.URL) (string, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("file URL specifies non-local host %q", u.Host)
+	}
+
+	p := u.Path
+
+	// file:///C:/path
+	if runtime.GOOS == "windows" && len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+
+	p = filepath.FromSlash(p)
+
+	if !filepath.IsAbs(p) {
+		return "", fmt.Errorf("file URL path %q is not absolute", u.Path)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,133 @@
+package proxylist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	type expectedProxy struct {
+		name            string
+		fallBackOnError bool
+	}
+
+	testCases := []struct {
+		desc     string
+		goproxy  string
+		expected []expectedProxy
+	}{
+		{
+			desc:    "single URL",
+			goproxy: "https://proxy.golang.org",
+			expected: []expectedProxy{
+				{name: "https://proxy.golang.org"},
+			},
+		},
+		{
+			desc:    "default",
+			goproxy: "https://proxy.golang.org,direct",
+			expected: []expectedProxy{
+				{name: "https://proxy.golang.org"},
+				{name: "direct"},
+			},
+		},
+		{
+			desc:    "mixed separators",
+			goproxy: "https://corp.example|https://proxy.golang.org,direct",
+			expected: []expectedProxy{
+				{name: "https://corp.example", fallBackOnError: true},
+				{name: "https://proxy.golang.org"},
+				{name: "direct"},
+			},
+		},
+		{
+			desc:    "implicit scheme",
+			goproxy: "corp.example/goproxy",
+			expected: []expectedProxy{
+				{name: "https://corp.example/goproxy"},
+			},
+		},
+		{
+			desc:    "file URL",
+			goproxy: "file:///var/cache/download,https://proxy.golang.org",
+			expected: []expectedProxy{
+				{name: "file:///var/cache/download"},
+				{name: "https://proxy.golang.org"},
+			},
+		},
+		{
+			desc:    "spaces and empty elements",
+			goproxy: " https://a.example ,, https://b.example ",
+			expected: []expectedProxy{
+				{name: "https://a.example"},
+				{name: "https://b.example"},
+			},
+		},
+		{
+			desc:    "off stops the list",
+			goproxy: "https://a.example,off,https://b.example",
+			expected: []expectedProxy{
+				{name: "https://a.example"},
+				{name: "off"},
+			},
+		},
+		{
+			desc:    "direct stops the list",
+			goproxy: "direct,https://b.example",
+			expected: []expectedProxy{
+				{name: "direct"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			proxies, err := Parse(test.goproxy)
+			require.NoError(t, err)
+
+			var actual []expectedProxy
+			for _, proxy := range proxies {
+				actual = append(actual, expectedProxy{name: proxy.Name, fallBackOnError: proxy.FallBackOnError})
+			}
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestParse_error(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		goproxy string
+	}{
+		{
+			desc:    "no entries",
+			goproxy: " , | ",
+		},
+		{
+			desc:    "invalid scheme",
+			goproxy: "ftp://proxy.example",
+		},
+		{
+			desc:    "reserved word",
+			goproxy: "noproxy",
+		},
+		{
+			desc:    "file URL with a remote host",
+			goproxy: "file://example.com/cache",
+		},
+		{
+			desc:    "relative file URL",
+			goproxy: "file:cache",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := Parse(test.goproxy)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+// Package testutil Helpers shared by the tests of the packages of the module.
+package testutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CreateZip creates a zip archive containing the files (name to content).
+func CreateZip(t testing.TB, files map[string]string) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+
+	w := zip.NewWriter(buf)
+
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+
+		_, err = io.WriteString(f, content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
@@ -0,0 +1,328 @@
+package modcache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ldez/grignotin/goproxy"
+	"github.com/ldez/grignotin/internal/fsutil"
+	"github.com/ldez/grignotin/internal/modver"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	modzip "golang.org/x/mod/zip"
+)
+
+// Download populates the cache with the files of the module version (.info, .mod, .zip, .ziphash, and the extracted directory).
+// See [Cache.DownloadWithContext].
+func (c *Cache) Download(client *goproxy.Client, modulePath, version string) (*Entry, error) {
+	return c.DownloadWithContext(context.Background(), client, modulePath, version)
+}
+
+// DownloadWithContext populates the cache with the files of the module version (.info, .mod, .zip, .ziphash, and the extracted directory).
+//
+// It follows the protocol of the go command, so the cache can be shared with the go command:
+// the module version is locked (".lock" file) during the download,
+// the files are written atomically,
+// and the extraction is marked by a ".partial" file until it is complete.
+// The files already present are not downloaded again.
+//
+// The content is not verified against go.sum or the checksum database.
+func (c *Cache) DownloadWithContext(ctx context.Context, client *goproxy.Client, modulePath, version string) (*Entry, error) {
+	lock, err := c.cachePath(modulePath, version, "lock")
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(lock), 0o777)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockPath(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = unlock() }()
+
+	err = c.downloadInfo(ctx, client, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.downloadMod(ctx, client, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.downloadZip(ctx, client, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.extract(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Stat(modulePath, version)
+}
+
+func (c *Cache) downloadInfo(ctx context.Context, client *goproxy.Client, modulePath, version string) error {
+	file, err := c.cachePath(modulePath, version, "info")
+	if err != nil {
+		return err
+	}
+
+	if exists(file) {
+		return nil
+	}
+
+	info, err := client.GetInfoWithContext(ctx, modulePath, version)
+	if err != nil {
+		return err
+	}
+
+	data, err := info.MarshalInfo()
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFile(file, bytes.NewReader(data))
+}
+
+func (c *Cache) downloadMod(ctx context.Context, client *goproxy.Client, modulePath, version string) error {
+	file, err := c.cachePath(modulePath, version, "mod")
+	if err != nil {
+		return err
+	}
+
+	if exists(file) {
+		return nil
+	}
+
+	data, err := client.GetRawModFileWithContext(ctx, modulePath, version)
+	if err != nil {
+		return err
+	}
+
+	err = fsutil.WriteFile(file, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	return rewriteVersionList(filepath.Dir(file))
+}
+
+// downloadZip downloads the zip into a temporary file, checks it, writes the .ziphash file,
+// and then renames the temporary file.
+func (c *Cache) downloadZip(ctx context.Context, client *goproxy.Client, modulePath, version string) error {
+	file, err := c.cachePath(modulePath, version, "zip")
+	if err != nil {
+		return err
+	}
+
+	if exists(file) && exists(file+"hash") {
+		return nil
+	}
+
+	body, err := client.DownloadSourcesWithContext(ctx, modulePath, version)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = body.Close() }()
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+"*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = io.Copy(tmp, body)
+	if err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = modzip.CheckZip(module.Version{Path: modulePath, Version: version}, tmp.Name())
+	if err != nil {
+		return fmt.Errorf("%s@%s: %w", modulePath, version, err)
+	}
+
+	hash, err := hashZip(tmp.Name())
+	if err != nil {
+		return err
+	}
+
+	err = fsutil.WriteFile(file+"hash", strings.NewReader(hash))
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// extract extracts the zip into the module directory.
+// A ".partial" file exists during the extraction: a directory with a ".partial" file is incomplete.
+func (c *Cache) extract(modulePath, version string) error {
+	dir, err := c.ModuleDir(modulePath, version)
+	if err != nil {
+		return err
+	}
+
+	partial, err := c.cachePath(modulePath, version, "partial")
+	if err != nil {
+		return err
+	}
+
+	if exists(dir) && !exists(partial) {
+		return nil
+	}
+
+	err = fsutil.WriteFile(partial, strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+
+	// Removes an incomplete extraction.
+	err = removeAll(dir)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dir), 0o777)
+	if err != nil {
+		return err
+	}
+
+	zip := strings.TrimSuffix(partial, "partial") + "zip"
+
+	err = modzip.Unzip(dir, module.Version{Path: modulePath, Version: version}, zip)
+	if err != nil {
+		return fmt.Errorf("%s@%s: %w", modulePath, version, err)
+	}
+
+	if !c.ReadWrite {
+		makeDirsReadOnly(dir)
+	}
+
+	return os.Remove(partial)
+}
+
+// rewriteVersionList rewrites the "list" file with the versions having a .mod file.
+// The "list" file is locked during the update.
+func rewriteVersionList(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var versions []string
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".mod")
+		if !ok {
+			continue
+		}
+
+		version, err := module.UnescapeVersion(name)
+		if err != nil || !modver.IsCanonical(version) {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	semver.Sort(versions)
+
+	var buf bytes.Buffer
+
+	for _, v := range versions {
+		buf.WriteString(v)
+		buf.WriteString("\n")
+	}
+
+	return editLocked(filepath.Join(dir, "list"), buf.Bytes())
+}
+
+// editLocked replaces the content of the file while holding a lock on the file.
+func editLocked(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	err = lockFile(f)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = unlockFile(f) }()
+
+	old, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(old, data) {
+		return nil
+	}
+
+	err = f.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteAt(data, 0)
+
+	return err
+}
+
+// makeDirsReadOnly makes the directories read-only, like the go command.
+func makeDirsReadOnly(dir string) {
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			_ = os.Chmod(p, 0o555)
+		}
+
+		return nil
+	})
+}
+
+// removeAll removes a directory, including the read-only directories.
+func removeAll(dir string) error {
+	if !exists(dir) {
+		return nil
+	}
+
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			_ = os.Chmod(p, 0o777)
+		}
+
+		return nil
+	})
+
+	err := os.RemoveAll(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
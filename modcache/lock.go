@@ -0,0 +1,25 @@
+package modcache
+
+import (
+	"errors"
+	"os"
+)
+
+// lockPath takes an exclusive lock on the file (created if needed), like the go command.
+// The lock is advisory: it only excludes the other processes using the same protocol.
+func lockPath(name string) (unlock func() error, err error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lockFile(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return func() error {
+		return errors.Join(unlockFile(f), f.Close())
+	}, nil
+}
@@ -0,0 +1,36 @@
+//go:build aix || (solaris && !illumos)
+
+package modcache
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// flock is not available on this platform: the lock is a fcntl lock (F_SETLKW) on the whole file, like the go command.
+// A fcntl lock is owned by the process: it does not exclude the other goroutines of the process.
+
+func lockFile(f *os.File) error {
+	return setLock(f, syscall.F_WRLCK)
+}
+
+func unlockFile(f *os.File) error {
+	return setLock(f, syscall.F_UNLCK)
+}
+
+func setLock(f *os.File, lockType int16) error {
+	lk := &syscall.Flock_t{
+		Type:   lockType,
+		Whence: io.SeekStart,
+		Start:  0,
+		Len:    0, // The whole file.
+	}
+
+	for {
+		err := syscall.FcntlFlock(f.Fd(), syscall.F_SETLKW, lk)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
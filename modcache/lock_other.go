@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package modcache
+
+import "os"
+
+// The file locks are not supported on this platform (e.g. plan9, wasip1):
+// the cache is not protected against concurrent writers.
+
+func lockFile(*os.File) error {
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package modcache
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileExclusiveLock = 0x00000002
+
+	// allBytes locks the whole file.
+	allBytes = ^uint32(0)
+)
+
+func lockFile(f *os.File) error {
+	ol := new(syscall.Overlapped)
+
+	r1, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock, 0, uintptr(allBytes), uintptr(allBytes), uintptr(unsafe.Pointer(ol)))
+	if r1 == 0 {
+		return err
+	}
+
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	ol := new(syscall.Overlapped)
+
+	r1, _, err := procUnlockFileEx.Call(f.Fd(), 0, uintptr(allBytes), uintptr(allBytes), uintptr(unsafe.Pointer(ol)))
+	if r1 == 0 {
+		return err
+	}
+
+	return nil
+}
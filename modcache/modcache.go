@@ -0,0 +1,307 @@
+// Package modcache A reader and writer of the module cache (GOMODCACHE).
+// https://go.dev/ref/mod#module-cache
+package modcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ldez/grignotin/goenv"
+	"github.com/ldez/grignotin/goproxy"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// Cache is a module cache.
+type Cache struct {
+	// Dir is the root directory of the module cache (GOMODCACHE).
+	Dir string
+
+	// ReadWrite leaves the extracted directories writable (like the "-modcacherw" flag of the go command).
+	// By default, the extracted directories are read-only.
+	ReadWrite bool
+}
+
+// New Creates a new Cache.
+func New(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// NewFromEnv Creates a new Cache located by the configuration of the go command (go env GOMODCACHE).
+func NewFromEnv(ctx context.Context) (*Cache, error) {
+	dir, err := goenv.GetOne(ctx, goenv.GOMODCACHE)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir == "" {
+		return nil, errors.New("GOMODCACHE is not defined")
+	}
+
+	return New(dir), nil
+}
+
+// Entry describes the files of a module version inside the cache.
+// The fields are the same as the output of "go mod download -json".
+// The paths of the missing files are empty.
+type Entry struct {
+	Path     string
+	Version  string
+	Info     string `json:",omitempty"`
+	GoMod    string `json:",omitempty"`
+	Zip      string `json:",omitempty"`
+	Dir      string `json:",omitempty"`
+	Sum      string `json:",omitempty"`
+	GoModSum string `json:",omitempty"`
+}
+
+// DownloadDir returns the directory containing the downloaded files of a module:
+//
+//	<GOMODCACHE>/cache/download/<escaped path>/@v
+func (c *Cache) DownloadDir(modulePath string) (string, error) {
+	escPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(c.Dir, "cache", "download", filepath.FromSlash(escPath), "@v"), nil
+}
+
+// ModuleDir returns the directory of the extracted module version:
+//
+//	<GOMODCACHE>/<escaped path>@<escaped version>
+func (c *Cache) ModuleDir(modulePath, version string) (string, error) {
+	escPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", err
+	}
+
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(c.Dir, filepath.FromSlash(escPath)+"@"+escVersion), nil
+}
+
+// cachePath returns the path of a file of a module version inside the download directory
+// (e.g. "<GOMODCACHE>/cache/download/<escaped path>/@v/<escaped version>.<suffix>").
+func (c *Cache) cachePath(modulePath, version, suffix string) (string, error) {
+	err := module.Check(modulePath, version)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := c.DownloadDir(modulePath)
+	if err != nil {
+		return "", err
+	}
+
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, escVersion+"."+suffix), nil
+}
+
+// Versions returns the versions of the module listed in the cache (the "list" file).
+func (c *Cache) Versions(modulePath string) ([]string, error) {
+	dir, err := c.DownloadDir(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "list"))
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(data)), nil
+}
+
+// Info returns the information of the module version (the ".info" file).
+func (c *Cache) Info(modulePath, version string) (*goproxy.VersionInfo, error) {
+	file, err := c.cachePath(modulePath, version, "info")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &goproxy.VersionInfo{}
+
+	err = json.Unmarshal(data, info)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return info, nil
+}
+
+// GoMod returns the content of the go.mod file of the module version (the ".mod" file).
+func (c *Cache) GoMod(modulePath, version string) ([]byte, error) {
+	file, err := c.cachePath(modulePath, version, "mod")
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(file)
+}
+
+// Stat describes the files of the module version present in the cache.
+// Returns an error wrapping [fs.ErrNotExist] if none of the files are present.
+func (c *Cache) Stat(modulePath, version string) (*Entry, error) {
+	entry := &Entry{Path: modulePath, Version: version}
+
+	info, err := c.cachePath(modulePath, version, "info")
+	if err != nil {
+		return nil, err
+	}
+
+	if exists(info) {
+		entry.Info = info
+	}
+
+	mod := strings.TrimSuffix(info, "info") + "mod"
+	if data, err := os.ReadFile(mod); err == nil {
+		entry.GoMod = mod
+
+		entry.GoModSum, err = goproxy.HashMod(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	zip := strings.TrimSuffix(info, "info") + "zip"
+	if hash, err := os.ReadFile(zip + "hash"); err == nil && exists(zip) {
+		entry.Zip = zip
+		entry.Sum = strings.TrimSpace(string(hash))
+	}
+
+	dir, err := c.ModuleDir(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists(dir) && !exists(strings.TrimSuffix(info, "info")+"partial") {
+		entry.Dir = dir
+	}
+
+	if entry.Info == "" && entry.GoMod == "" && entry.Zip == "" && entry.Dir == "" {
+		return nil, fmt.Errorf("%s@%s: %w", modulePath, version, fs.ErrNotExist)
+	}
+
+	return entry, nil
+}
+
+// List returns the module versions present in the download cache,
+// sorted by module path and by version.
+func (c *Cache) List() ([]module.Version, error) {
+	root := filepath.Join(c.Dir, "cache", "download")
+
+	var mods []module.Version
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		// The checksum database cache is not a module.
+		if rel == "sumdb" {
+			return filepath.SkipDir
+		}
+
+		if d.Name() != "@v" {
+			return nil
+		}
+
+		modulePath, err := module.UnescapePath(filepath.ToSlash(filepath.Dir(rel)))
+		if err != nil {
+			// Not a module directory.
+			return filepath.SkipDir
+		}
+
+		versions, err := readVersions(p)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			mods = append(mods, module.Version{Path: modulePath, Version: version})
+		}
+
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	module.Sort(mods)
+
+	return mods, nil
+}
+
+// readVersions returns the versions having a file (.info, .mod, or .zip) inside a download directory.
+func readVersions(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if ext != ".info" && ext != ".mod" && ext != ".zip" {
+			continue
+		}
+
+		version, err := module.UnescapeVersion(strings.TrimSuffix(entry.Name(), ext))
+		if err != nil {
+			continue
+		}
+
+		if !slices.Contains(versions, version) {
+			versions = append(versions, version)
+		}
+	}
+
+	semver.Sort(versions)
+
+	return versions, nil
+}
+
+// hashZip computes the "h1:" hash of a module zip file.
+func hashZip(file string) (string, error) {
+	return dirhash.HashZip(file, dirhash.Hash1)
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
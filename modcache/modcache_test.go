@@ -0,0 +1,217 @@
+package modcache
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ldez/grignotin/goproxy"
+	"github.com/ldez/grignotin/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+)
+
+const testModFile = "module github.com/ldez/Grignotin\n\ngo 1.22\n"
+
+func setupProxy(t *testing.T) (*goproxy.Client, *atomic.Int64) {
+	t.Helper()
+
+	archive := testutil.CreateZip(t, map[string]string{
+		"github.com/ldez/Grignotin@v0.1.0/go.mod":     testModFile,
+		"github.com/ldez/Grignotin@v0.1.0/foo/foo.go": "package foo\n",
+	})
+
+	var zipCalls atomic.Int64
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /github.com/ldez/!grignotin/@v/v0.1.0.info",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = rw.Write([]byte(`{"Version":"v0.1.0","Time":"2020-01-01T00:00:00Z","Origin":{"VCS":"git","URL":"https://github.com/ldez/grignotin","Hash":"818c5a804067a4a1d5a0cbd2b7c5bf61d5cdd4c4","Ref":"refs/tags/v0.1.0"}}`))
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/!grignotin/@v/v0.1.0.mod",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = rw.Write([]byte(testModFile))
+		})
+
+	mux.HandleFunc("GET /github.com/ldez/!grignotin/@v/v0.1.0.zip",
+		func(rw http.ResponseWriter, _ *http.Request) {
+			zipCalls.Add(1)
+			_, _ = rw.Write(archive)
+		})
+
+	return goproxy.NewClient(server.URL), &zipCalls
+}
+
+func TestCache_Download(t *testing.T) {
+	client, zipCalls := setupProxy(t)
+
+	cache := New(t.TempDir())
+	t.Cleanup(func() { _ = removeAll(cache.Dir) })
+
+	entry, err := cache.Download(client, "github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	downloadDir := filepath.Join(cache.Dir, "cache", "download", "github.com", "ldez", "!grignotin", "@v")
+
+	expected := &Entry{
+		Path:     "github.com/ldez/Grignotin",
+		Version:  "v0.1.0",
+		Info:     filepath.Join(downloadDir, "v0.1.0.info"),
+		GoMod:    filepath.Join(downloadDir, "v0.1.0.mod"),
+		Zip:      filepath.Join(downloadDir, "v0.1.0.zip"),
+		Dir:      filepath.Join(cache.Dir, "github.com", "ldez", "!grignotin@v0.1.0"),
+		Sum:      entry.Sum,
+		GoModSum: "h1:wNL4X5fH0MBuEDAUNHitgcQDIKVP00uMulO1IEX6Q4w=",
+	}
+
+	assert.Equal(t, expected, entry)
+	assert.Regexp(t, `^h1:.{44}$`, entry.Sum)
+
+	content, err := os.ReadFile(filepath.Join(entry.Dir, "foo", "foo.go"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "package foo\n", string(content))
+
+	info, err := os.Stat(filepath.Join(entry.Dir, "foo"))
+	require.NoError(t, err)
+
+	assert.Equal(t, fs.FileMode(0o555), info.Mode().Perm())
+
+	assert.NoFileExists(t, filepath.Join(downloadDir, "v0.1.0.partial"))
+
+	// Already in the cache.
+	_, err = cache.Download(client, "github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), zipCalls.Load())
+}
+
+func TestCache_Download_concurrent(t *testing.T) {
+	client, zipCalls := setupProxy(t)
+
+	cache := &Cache{Dir: t.TempDir(), ReadWrite: true}
+
+	var wg sync.WaitGroup
+
+	for range 5 {
+		wg.Go(func() {
+			_, err := cache.Download(client, "github.com/ldez/Grignotin", "v0.1.0")
+			assert.NoError(t, err)
+		})
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(1), zipCalls.Load())
+}
+
+func TestCache_Download_partial(t *testing.T) {
+	client, _ := setupProxy(t)
+
+	cache := &Cache{Dir: t.TempDir(), ReadWrite: true}
+
+	_, err := cache.Download(client, "github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	// Simulates an interrupted extraction.
+	dir, err := cache.ModuleDir("github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "go.mod")))
+
+	partial, err := cache.cachePath("github.com/ldez/Grignotin", "v0.1.0", "partial")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(partial, nil, 0o600))
+
+	entry, err := cache.Stat("github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Empty(t, entry.Dir)
+
+	entry, err = cache.Download(client, "github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, dir, entry.Dir)
+	assert.FileExists(t, filepath.Join(dir, "go.mod"))
+	assert.NoFileExists(t, partial)
+}
+
+func TestCache_read(t *testing.T) {
+	client, _ := setupProxy(t)
+
+	cache := &Cache{Dir: t.TempDir(), ReadWrite: true}
+
+	_, err := cache.Download(client, "github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	versions, err := cache.Versions("github.com/ldez/Grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.1.0"}, versions)
+
+	info, err := cache.Info("github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.1.0", info.Version)
+
+	expectedOrigin := &goproxy.Origin{
+		VCS:  "git",
+		URL:  "https://github.com/ldez/grignotin",
+		Hash: "818c5a804067a4a1d5a0cbd2b7c5bf61d5cdd4c4",
+		Ref:  "refs/tags/v0.1.0",
+	}
+
+	assert.Equal(t, expectedOrigin, info.Origin)
+
+	mod, err := cache.GoMod("github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, testModFile, string(mod))
+
+	// The checksum database cache must be ignored.
+	require.NoError(t, os.MkdirAll(filepath.Join(cache.Dir, "cache", "download", "sumdb", "sum.golang.org", "lookup"), 0o755))
+
+	mods, err := cache.List()
+	require.NoError(t, err)
+
+	assert.Equal(t, []module.Version{{Path: "github.com/ldez/Grignotin", Version: "v0.1.0"}}, mods)
+
+	_, err = cache.Stat("github.com/ldez/Grignotin", "v0.2.0")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestCache_List_empty(t *testing.T) {
+	cache := New(t.TempDir())
+
+	mods, err := cache.List()
+	require.NoError(t, err)
+
+	assert.Empty(t, mods)
+}
+
+func Test_rewriteVersionList(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"v0.1.0.mod", "v0.1.0.info", "v2.0.0+incompatible.mod", "v1.0.0-!r!c.1.mod", "master.mod"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	err := rewriteVersionList(dir)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "list"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.1.0\nv1.0.0-RC.1\nv2.0.0+incompatible\n", string(content))
+}
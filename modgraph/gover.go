@@ -0,0 +1,60 @@
+package modgraph
+
+import (
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// compareGoVersions compares two Go versions (e.g. "1.21", "1.21rc1", "1.21.3").
+// A language version ("1.21") is lower than its pre-releases ("1.21rc1"), lower than its releases ("1.21.0").
+// An empty version is lower than all the versions.
+func compareGoVersions(a, b string) int {
+	return semver.Compare(goVersionToSemver(a), goVersionToSemver(b))
+}
+
+// goVersionToSemver converts a Go version to a semantic version:
+// "1.21" -> "v1.21.0-0", "1.21rc1" -> "v1.21.0-rc1", "1.21.3" -> "v1.21.3".
+func goVersionToSemver(v string) string {
+	if v == "" {
+		return ""
+	}
+
+	i := strings.IndexFunc(v, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+
+	release, prerelease := v, ""
+	if i >= 0 {
+		release, prerelease = v[:i], v[i:]
+	}
+
+	switch strings.Count(release, ".") {
+	case 0:
+		release += ".0.0"
+
+	case 1:
+		release += ".0"
+
+		if prerelease == "" {
+			prerelease = "0"
+		}
+	}
+
+	if prerelease != "" {
+		return "v" + release + "-" + prerelease
+	}
+
+	return "v" + release
+}
+
+// toolchainGoVersion returns the Go version of a toolchain name (e.g. "go1.21.3" -> "1.21.3").
+func toolchainGoVersion(name string) string {
+	v := strings.TrimPrefix(name, "go")
+
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	return v
+}
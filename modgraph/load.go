@@ -0,0 +1,264 @@
+package modgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ldez/grignotin/goproxy"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// pruningGoVersion is the first Go version with graph pruning.
+// https://go.dev/ref/mod#graph-pruning
+const pruningGoVersion = "1.17"
+
+// Load loads the module graph of a module version (the main module) with the go.mod files provided by the proxy.
+// See [LoadModFile].
+func Load(ctx context.Context, client *goproxy.Client, modulePath, version string) (*Graph, error) {
+	raw, err := client.GetRawModFileWithContext(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := modfile.Parse("go.mod", raw, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return load(ctx, client, module.Version{Path: modulePath, Version: version}, file)
+}
+
+// LoadModFile loads the module graph of a go.mod file (the main module) with the go.mod files provided by the proxy,
+// and selects the versions with the minimal version selection (MVS).
+//
+// The "replace" and "exclude" directives of the main module apply to the whole graph:
+//   - a replaced module version uses the go.mod file of its replacement.
+//   - the requirements of an excluded module version are ignored.
+//
+// If the "go" directive of the main module is at least 1.17, the graph is pruned:
+// only the immediate requirements of the dependencies at go 1.17 or higher are loaded.
+//
+// The requirements of the modules replaced by a local directory are not loaded:
+// the proxy cannot provide their go.mod files.
+func LoadModFile(ctx context.Context, client *goproxy.Client, file *modfile.File) (*Graph, error) {
+	if file.Module == nil {
+		return nil, errors.New("go.mod: missing module directive")
+	}
+
+	return load(ctx, client, module.Version{Path: file.Module.Mod.Path}, file)
+}
+
+// summary is the content of a go.mod file used by the graph.
+type summary struct {
+	goVersion string
+	require   []module.Version
+}
+
+// pruned reports whether the go.mod file has graph pruning.
+func (s *summary) pruned() bool {
+	return compareGoVersions(s.goVersion, pruningGoVersion) >= 0
+}
+
+// loadItem is a module version to load.
+type loadItem struct {
+	mod    module.Version
+	pruned bool
+}
+
+type loader struct {
+	client *goproxy.Client
+	graph  *Graph
+
+	replace map[module.Version]module.Version
+	exclude map[module.Version]bool
+
+	summaries map[module.Version]*summary
+
+	// loaded and loadedUnpruned contain the queued module versions by kind of pruning.
+	loaded         map[module.Version]bool
+	loadedUnpruned map[module.Version]bool
+}
+
+func load(ctx context.Context, client *goproxy.Client, main module.Version, file *modfile.File) (*Graph, error) {
+	l := &loader{
+		client:         client,
+		graph:          newGraph(main),
+		replace:        make(map[module.Version]module.Version),
+		exclude:        make(map[module.Version]bool),
+		summaries:      make(map[module.Version]*summary),
+		loaded:         make(map[module.Version]bool),
+		loadedUnpruned: make(map[module.Version]bool),
+	}
+
+	for _, r := range file.Replace {
+		l.replace[r.Old] = r.New
+	}
+
+	for _, e := range file.Exclude {
+		l.exclude[e.Mod] = true
+	}
+
+	mainSummary := l.summarize(file)
+
+	l.graph.reqs[main] = mainSummary.require
+
+	if file.Go != nil {
+		l.graph.GoVersion = file.Go.Version
+	}
+
+	if file.Toolchain != nil {
+		l.graph.Toolchain = file.Toolchain.Name
+	}
+
+	var queue []loadItem
+
+	for _, r := range mainSummary.require {
+		queue = l.enqueue(queue, r, mainSummary.pruned())
+	}
+
+	for len(queue) > 0 {
+		var err error
+
+		queue, err = l.loadLevel(ctx, queue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l.graph.selectVersions()
+
+	return l.graph, nil
+}
+
+// enqueue adds a module version to the queue, if it is not already queued with the same kind of pruning.
+func (l *loader) enqueue(queue []loadItem, m module.Version, pruned bool) []loadItem {
+	if m.Path == l.graph.Main.Path || m.Version == "none" {
+		return queue
+	}
+
+	seen := l.loaded
+	if !pruned {
+		seen = l.loadedUnpruned
+	}
+
+	if seen[m] {
+		return queue
+	}
+
+	seen[m] = true
+
+	return append(queue, loadItem{mod: m, pruned: pruned})
+}
+
+// loadLevel loads the go.mod files of the queued module versions, and returns the next module versions to load.
+func (l *loader) loadLevel(ctx context.Context, queue []loadItem) ([]loadItem, error) {
+	err := l.fetchSummaries(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	var next []loadItem
+
+	for _, item := range queue {
+		s := l.summaries[item.mod]
+
+		l.graph.reqs[item.mod] = s.require
+		l.graph.goVersions[item.mod] = s.goVersion
+
+		// The requirements of a module without graph pruning are not sufficient:
+		// its full transitive graph is loaded.
+		if item.pruned && s.pruned() {
+			continue
+		}
+
+		for _, r := range s.require {
+			next = l.enqueue(next, r, item.pruned && s.pruned())
+		}
+	}
+
+	return next, nil
+}
+
+// fetchSummaries gets the go.mod files of the module versions without summary.
+func (l *loader) fetchSummaries(ctx context.Context, queue []loadItem) error {
+	var (
+		missing []module.Version
+		targets []module.Version
+	)
+
+	for _, item := range queue {
+		if _, ok := l.summaries[item.mod]; ok {
+			continue
+		}
+
+		target := l.replacement(item.mod)
+
+		// A local directory cannot be read from the proxy.
+		if target.Version == "" {
+			l.summaries[item.mod] = &summary{}
+
+			continue
+		}
+
+		// The same module version can be queued with different kinds of pruning.
+		l.summaries[item.mod] = nil
+
+		missing = append(missing, item.mod)
+		targets = append(targets, target)
+	}
+
+	results := l.client.GetRawModFileBatchWithContext(ctx, targets)
+
+	for i, result := range results {
+		if result.Err != nil {
+			return fmt.Errorf("loading go.mod of %s: %w", missing[i], result.Err)
+		}
+
+		file, err := modfile.ParseLax("go.mod", result.Value, nil)
+		if err != nil {
+			return fmt.Errorf("parsing go.mod of %s: %w", missing[i], err)
+		}
+
+		l.summaries[missing[i]] = l.summarize(file)
+	}
+
+	return nil
+}
+
+// summarize extracts the "go" directive and the requirements (without the excluded versions) of a go.mod file.
+func (l *loader) summarize(file *modfile.File) *summary {
+	s := &summary{}
+
+	if file.Go != nil {
+		s.goVersion = file.Go.Version
+	}
+
+	for _, r := range file.Require {
+		if l.exclude[r.Mod] || !semver.IsValid(r.Mod.Version) {
+			continue
+		}
+
+		s.require = append(s.require, r.Mod)
+	}
+
+	return s
+}
+
+// replacement returns the module version providing the go.mod file of a module version.
+func (l *loader) replacement(m module.Version) module.Version {
+	r, ok := l.replace[m]
+	if !ok {
+		r, ok = l.replace[module.Version{Path: m.Path}]
+	}
+
+	if !ok {
+		return m
+	}
+
+	l.graph.replacements[m] = r
+
+	return r
+}
@@ -0,0 +1,158 @@
+// Package modgraph A module graph and the minimal version selection (MVS), computed with a module proxy.
+// https://go.dev/ref/mod#minimal-version-selection
+package modgraph
+
+import (
+	"cmp"
+	"slices"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Graph is the requirement graph of a main module.
+type Graph struct {
+	// Main is the main module.
+	// The version is empty when the graph is loaded from a go.mod file.
+	Main module.Version
+
+	// GoVersion is the "go" directive of the main module.
+	GoVersion string
+
+	// Toolchain is the "toolchain" directive of the main module.
+	Toolchain string
+
+	reqs         map[module.Version][]module.Version
+	goVersions   map[module.Version]string
+	replacements map[module.Version]module.Version
+	selected     map[string]string
+}
+
+func newGraph(main module.Version) *Graph {
+	return &Graph{
+		Main:         main,
+		reqs:         make(map[module.Version][]module.Version),
+		goVersions:   make(map[module.Version]string),
+		replacements: make(map[module.Version]module.Version),
+		selected:     make(map[string]string),
+	}
+}
+
+// Requirements returns the requirements of a module version,
+// after the replacements and the exclusions of the main module.
+// It returns nil if the requirements of the module version are not loaded (pruned graph).
+func (g *Graph) Requirements(m module.Version) []module.Version {
+	return slices.Clone(g.reqs[m])
+}
+
+// IsLoaded reports whether the requirements of the module version are loaded.
+// The requirements of the dependencies of a module with graph pruning (go >= 1.17) are not loaded.
+func (g *Graph) IsLoaded(m module.Version) bool {
+	_, ok := g.reqs[m]
+
+	return ok
+}
+
+// Replacement returns the replacement of a module version.
+// The version of a replacement by a local directory is empty.
+func (g *Graph) Replacement(m module.Version) (module.Version, bool) {
+	r, ok := g.replacements[m]
+
+	return r, ok
+}
+
+// GoVersionOf returns the "go" directive of the go.mod file of a module version.
+// It returns an empty string if the go.mod file is not loaded, or does not have a "go" directive.
+func (g *Graph) GoVersionOf(m module.Version) string {
+	return g.goVersions[m]
+}
+
+// Selected returns the version of the module path selected by MVS.
+// It returns an empty string if the module path is not in the graph.
+func (g *Graph) Selected(path string) string {
+	return g.selected[path]
+}
+
+// BuildList returns the main module, followed by the selected versions of the other modules sorted by path.
+func (g *Graph) BuildList() []module.Version {
+	list := make([]module.Version, 0, len(g.selected))
+
+	for path, version := range g.selected {
+		if path != g.Main.Path {
+			list = append(list, module.Version{Path: path, Version: version})
+		}
+	}
+
+	slices.SortFunc(list, func(a, b module.Version) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	return append([]module.Version{g.Main}, list...)
+}
+
+// Modules returns all the module versions of the graph (selected or not), sorted by path and version.
+func (g *Graph) Modules() []module.Version {
+	seen := map[module.Version]bool{g.Main: true}
+
+	for m, reqs := range g.reqs {
+		seen[m] = true
+
+		for _, r := range reqs {
+			seen[r] = true
+		}
+	}
+
+	modules := make([]module.Version, 0, len(seen))
+	for m := range seen {
+		modules = append(modules, m)
+	}
+
+	module.Sort(modules)
+
+	return modules
+}
+
+// RequiredGoVersion returns the highest "go" directive of the main module and of the selected versions.
+// The "go" directive of the main module must be at least this version.
+func (g *Graph) RequiredGoVersion() string {
+	version := g.GoVersion
+
+	for _, m := range g.BuildList() {
+		if v := g.goVersions[m]; compareGoVersions(v, version) > 0 {
+			version = v
+		}
+	}
+
+	return version
+}
+
+// ToolchainVersion returns the minimum toolchain of the main module:
+// the "toolchain" directive if it is not older than the "go" directive, otherwise "go" followed by the "go" directive.
+// The "toolchain" directives of the dependencies are ignored, like the go command.
+func (g *Graph) ToolchainVersion() string {
+	if g.Toolchain != "" && compareGoVersions(toolchainGoVersion(g.Toolchain), g.GoVersion) >= 0 {
+		return g.Toolchain
+	}
+
+	if g.GoVersion == "" {
+		return ""
+	}
+
+	return "go" + g.GoVersion
+}
+
+// selectVersions applies MVS: the highest version of each module path in the graph.
+// The version of the main module is always selected.
+func (g *Graph) selectVersions() {
+	for _, m := range g.Modules() {
+		if m.Version == "none" {
+			continue
+		}
+
+		if current, ok := g.selected[m.Path]; !ok || semver.Compare(m.Version, current) > 0 {
+			g.selected[m.Path] = m.Version
+		}
+	}
+
+	g.selected[g.Main.Path] = g.Main.Version
+}
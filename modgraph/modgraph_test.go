@@ -0,0 +1,296 @@
+package modgraph
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ldez/grignotin/goproxy"
+	"github.com/ldez/grignotin/proxyserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// testModFiles are the go.mod files of the test proxy.
+var testModFiles = map[string]string{
+	"example.com/a@v1.0.0": `module example.com/a
+
+go 1.21
+
+require example.com/b v1.0.0
+`,
+	"example.com/b@v1.0.0": `module example.com/b
+
+go 1.21
+
+require example.com/c v1.1.0
+`,
+	"example.com/c@v1.1.0": `module example.com/c
+
+go 1.16
+`,
+	"example.com/c@v1.2.0": `module example.com/c
+
+go 1.22.1
+`,
+	"example.com/old@v1.0.0": `module example.com/old
+
+require (
+	example.com/c v1.2.0
+	example.com/d v1.0.0
+)
+`,
+	"example.com/d@v1.0.0": `module example.com/d
+`,
+	"example.com/fork@v1.0.1": `module example.com/fork
+
+go 1.21
+
+require example.com/d v1.0.0
+`,
+	"example.com/root@v1.0.0": `module example.com/root
+
+go 1.21
+
+require example.com/a v1.0.0
+`,
+}
+
+// testProxy is a storage counting the requests for the go.mod files.
+type testProxy struct {
+	*proxyserver.MemoryStorage
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func setupProxy(t *testing.T) (*goproxy.Client, *testProxy) {
+	t.Helper()
+
+	proxy := &testProxy{MemoryStorage: proxyserver.NewMemoryStorage(), calls: map[string]int{}}
+
+	for mv, content := range testModFiles {
+		modulePath, version, _ := strings.Cut(mv, "@")
+
+		require.NoError(t, proxy.Add(modulePath, version, time.Time{}, []byte(content), nil))
+	}
+
+	server := httptest.NewServer(proxyserver.NewHandler(proxy))
+	t.Cleanup(server.Close)
+
+	return goproxy.NewClient(server.URL), proxy
+}
+
+// Mod returns the go.mod file of the module version.
+func (p *testProxy) Mod(ctx context.Context, modulePath, version string) ([]byte, error) {
+	p.mu.Lock()
+	p.calls[modulePath+"@"+version]++
+	p.mu.Unlock()
+
+	return p.MemoryStorage.Mod(ctx, modulePath, version)
+}
+
+func (p *testProxy) count(mv string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.calls[mv]
+}
+
+func parseModFile(t *testing.T, content string) *modfile.File {
+	t.Helper()
+
+	file, err := modfile.Parse("go.mod", []byte(content), nil)
+	require.NoError(t, err)
+
+	return file
+}
+
+func TestLoadModFile(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		modFile           string
+		expected          []string
+		expectedNotLoaded []string
+		expectedGo        string
+		expectedToolchain string
+	}{
+		{
+			desc: "pruned graph",
+			modFile: `module example.com/main
+
+go 1.21
+
+require (
+	example.com/a v1.0.0
+	example.com/c v1.1.0
+)
+`,
+			// The requirements of example.com/b are not loaded: example.com/a and example.com/b are pruned.
+			expected:          []string{"example.com/main", "example.com/a@v1.0.0", "example.com/b@v1.0.0", "example.com/c@v1.1.0"},
+			expectedNotLoaded: []string{"example.com/b@v1.0.0"},
+			expectedGo:        "1.21",
+			expectedToolchain: "go1.21",
+		},
+		{
+			desc: "unpruned graph",
+			modFile: `module example.com/main
+
+go 1.16
+
+require (
+	example.com/a v1.0.0
+	example.com/old v1.0.0
+)
+`,
+			expected:          []string{"example.com/main", "example.com/a@v1.0.0", "example.com/b@v1.0.0", "example.com/c@v1.2.0", "example.com/d@v1.0.0", "example.com/old@v1.0.0"},
+			expectedGo:        "1.22.1",
+			expectedToolchain: "go1.16",
+		},
+		{
+			desc: "unpruned dependency",
+			modFile: `module example.com/main
+
+go 1.21
+
+toolchain go1.22.3
+
+require example.com/old v1.0.0
+`,
+			expected:          []string{"example.com/main", "example.com/c@v1.2.0", "example.com/d@v1.0.0", "example.com/old@v1.0.0"},
+			expectedGo:        "1.22.1",
+			expectedToolchain: "go1.22.3",
+		},
+		{
+			desc: "replace and exclude",
+			modFile: `module example.com/main
+
+go 1.21
+
+require (
+	example.com/a v1.0.0
+	example.com/old v1.0.0
+)
+
+replace example.com/a v1.0.0 => example.com/fork v1.0.1
+
+replace example.com/d => ./d
+
+exclude example.com/c v1.2.0
+`,
+			expected:          []string{"example.com/main", "example.com/a@v1.0.0", "example.com/d@v1.0.0", "example.com/old@v1.0.0"},
+			expectedGo:        "1.21",
+			expectedToolchain: "go1.21",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, _ := setupProxy(t)
+
+			graph, err := LoadModFile(t.Context(), client, parseModFile(t, test.modFile))
+			require.NoError(t, err)
+
+			var buildList []string
+			for _, m := range graph.BuildList() {
+				buildList = append(buildList, m.String())
+			}
+
+			assert.Equal(t, test.expected, buildList)
+
+			for _, mv := range test.expectedNotLoaded {
+				path, version, _ := strings.Cut(mv, "@")
+
+				assert.False(t, graph.IsLoaded(module.Version{Path: path, Version: version}))
+			}
+
+			assert.Equal(t, test.expectedGo, graph.RequiredGoVersion())
+			assert.Equal(t, test.expectedToolchain, graph.ToolchainVersion())
+		})
+	}
+}
+
+func TestLoadModFile_replacement(t *testing.T) {
+	client, proxy := setupProxy(t)
+
+	file := parseModFile(t, `module example.com/main
+
+go 1.21
+
+require example.com/a v1.0.0
+
+replace example.com/a v1.0.0 => example.com/fork v1.0.1
+`)
+
+	graph, err := LoadModFile(t.Context(), client, file)
+	require.NoError(t, err)
+
+	a := module.Version{Path: "example.com/a", Version: "v1.0.0"}
+
+	replacement, ok := graph.Replacement(a)
+	require.True(t, ok)
+
+	assert.Equal(t, module.Version{Path: "example.com/fork", Version: "v1.0.1"}, replacement)
+	assert.Equal(t, []module.Version{{Path: "example.com/d", Version: "v1.0.0"}}, graph.Requirements(a))
+
+	assert.Equal(t, 0, proxy.count("example.com/a@v1.0.0"))
+	assert.Equal(t, 1, proxy.count("example.com/fork@v1.0.1"))
+}
+
+func TestLoad(t *testing.T) {
+	client, proxy := setupProxy(t)
+
+	graph, err := Load(t.Context(), client, "example.com/root", "v1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, module.Version{Path: "example.com/root", Version: "v1.0.0"}, graph.Main)
+	assert.Equal(t, "v1.0.0", graph.Selected("example.com/root"))
+	assert.Equal(t, "v1.0.0", graph.Selected("example.com/b"))
+	assert.Empty(t, graph.Selected("example.com/c"))
+
+	assert.Equal(t, "1.21", graph.GoVersionOf(module.Version{Path: "example.com/a", Version: "v1.0.0"}))
+
+	// The go.mod files of the pruned modules are not loaded.
+	assert.Equal(t, 0, proxy.count("example.com/b@v1.0.0"))
+}
+
+func TestLoad_missingModFile(t *testing.T) {
+	client, _ := setupProxy(t)
+
+	file := parseModFile(t, `module example.com/main
+
+go 1.21
+
+require example.com/unknown v1.0.0
+`)
+
+	_, err := LoadModFile(t.Context(), client, file)
+	require.Error(t, err)
+}
+
+func Test_compareGoVersions(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "1.21", b: "1.21", expected: 0},
+		{a: "1.21", b: "1.21rc1", expected: -1},
+		{a: "1.21rc1", b: "1.21.0", expected: -1},
+		{a: "1.21.0", b: "1.21.3", expected: -1},
+		{a: "1.9", b: "1.17", expected: -1},
+		{a: "1.22", b: "1.21.9", expected: 1},
+		{a: "", b: "1.16", expected: -1},
+		{a: "2", b: "1.22", expected: 1},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, compareGoVersions(test.a, test.b), "%s vs %s", test.a, test.b)
+	}
+}
@@ -0,0 +1,151 @@
+package modgraph
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Explanation explains why a module is in the graph, like "go mod why -m".
+type Explanation struct {
+	// Path is the module path.
+	Path string
+
+	// Version is the version selected by MVS.
+	// It is empty if the module is not in the graph.
+	Version string
+
+	// Paths are the shortest requirement paths from the main module to the requirements on the module:
+	// one path by module version requiring the module, the shortest paths first.
+	// The last element of a path is the required module version (not always the selected version).
+	Paths [][]module.Version
+
+	// SelectedBy are the module versions requiring the selected version.
+	SelectedBy []module.Version
+
+	// Pruned are the shortest requirement paths from the main module to the module versions with pruned requirements.
+	// The requirements of these module versions are not loaded: they can also require the module.
+	Pruned [][]module.Version
+}
+
+// String returns the explanation as text, in a format close to "go mod why -m".
+func (e *Explanation) String() string {
+	b := &strings.Builder{}
+
+	if e.Version == "" && len(e.Paths) == 0 {
+		_, _ = fmt.Fprintf(b, "# %s\n(main module does not need module %s)\n", e.Path, e.Path)
+
+		return b.String()
+	}
+
+	_, _ = fmt.Fprintf(b, "# %s\n", module.Version{Path: e.Path, Version: e.Version})
+
+	for _, m := range e.SelectedBy {
+		_, _ = fmt.Fprintf(b, "# selected by %s\n", m)
+	}
+
+	writePaths(b, e.Paths)
+
+	if len(e.Pruned) > 0 {
+		b.WriteString("\n# pruned requirements\n")
+
+		writePaths(b, e.Pruned)
+	}
+
+	return b.String()
+}
+
+func writePaths(b *strings.Builder, paths [][]module.Version) {
+	for i, p := range paths {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		for _, m := range p {
+			_, _ = fmt.Fprintln(b, m)
+		}
+	}
+}
+
+// Why explains why a module path is in the graph:
+// the shortest requirement paths from the main module, the module versions requiring the selected version,
+// and the paths to the module versions with pruned requirements.
+//
+// The paths go through all the module versions of the graph, selected or not, like "go mod graph".
+func (g *Graph) Why(path string) *Explanation {
+	e := &Explanation{Path: path}
+
+	if path == g.Main.Path {
+		e.Version = g.Main.Version
+		e.Paths = [][]module.Version{{g.Main}}
+
+		return e
+	}
+
+	version, ok := g.selected[path]
+	if !ok {
+		return e
+	}
+
+	e.Version = version
+
+	order, parents := g.shortestPaths()
+
+	for _, m := range order {
+		if !g.IsLoaded(m) {
+			if m.Path != path {
+				e.Pruned = append(e.Pruned, pathTo(parents, m))
+			}
+
+			continue
+		}
+
+		for _, r := range g.reqs[m] {
+			if r.Path != path {
+				continue
+			}
+
+			e.Paths = append(e.Paths, append(pathTo(parents, m), r))
+
+			if r.Version == version {
+				e.SelectedBy = append(e.SelectedBy, m)
+			}
+		}
+	}
+
+	return e
+}
+
+// shortestPaths walks the graph breadth-first from the main module.
+// It returns the module versions in the order of the walk, and the predecessor of each module version on a shortest path.
+func (g *Graph) shortestPaths() ([]module.Version, map[module.Version]module.Version) {
+	order := []module.Version{g.Main}
+	parents := map[module.Version]module.Version{g.Main: {}}
+
+	for i := 0; i < len(order); i++ {
+		m := order[i]
+
+		for _, r := range g.reqs[m] {
+			if _, seen := parents[r]; seen || r.Path == g.Main.Path {
+				continue
+			}
+
+			parents[r] = m
+			order = append(order, r)
+		}
+	}
+
+	return order, parents
+}
+
+// pathTo returns the requirement path from the main module to a module version.
+func pathTo(parents map[module.Version]module.Version, m module.Version) []module.Version {
+	var path []module.Version
+
+	for current := m; current.Path != ""; current = parents[current] {
+		path = append([]module.Version{current}, path...)
+	}
+
+	return path
+}
@@ -0,0 +1,132 @@
+package modgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+)
+
+func TestGraph_Why(t *testing.T) {
+	mainModule := module.Version{Path: "example.com/main"}
+	a := module.Version{Path: "example.com/a", Version: "v1.0.0"}
+	b := module.Version{Path: "example.com/b", Version: "v1.0.0"}
+	c110 := module.Version{Path: "example.com/c", Version: "v1.1.0"}
+	c120 := module.Version{Path: "example.com/c", Version: "v1.2.0"}
+	old := module.Version{Path: "example.com/old", Version: "v1.0.0"}
+
+	testCases := []struct {
+		desc     string
+		modFile  string
+		path     string
+		expected *Explanation
+		text     string
+	}{
+		{
+			desc: "pruned graph",
+			modFile: `module example.com/main
+
+go 1.21
+
+require (
+	example.com/a v1.0.0
+	example.com/c v1.1.0
+)
+`,
+			path: "example.com/c",
+			expected: &Explanation{
+				Path:       "example.com/c",
+				Version:    "v1.1.0",
+				Paths:      [][]module.Version{{mainModule, c110}},
+				SelectedBy: []module.Version{mainModule},
+				Pruned:     [][]module.Version{{mainModule, a, b}},
+			},
+			text: `# example.com/c@v1.1.0
+# selected by example.com/main
+example.com/main
+example.com/c@v1.1.0
+
+# pruned requirements
+example.com/main
+example.com/a@v1.0.0
+example.com/b@v1.0.0
+`,
+		},
+		{
+			desc: "unpruned graph",
+			modFile: `module example.com/main
+
+go 1.16
+
+require (
+	example.com/a v1.0.0
+	example.com/old v1.0.0
+)
+`,
+			path: "example.com/c",
+			expected: &Explanation{
+				Path:       "example.com/c",
+				Version:    "v1.2.0",
+				Paths:      [][]module.Version{{mainModule, old, c120}, {mainModule, a, b, c110}},
+				SelectedBy: []module.Version{old},
+			},
+			text: `# example.com/c@v1.2.0
+# selected by example.com/old@v1.0.0
+example.com/main
+example.com/old@v1.0.0
+example.com/c@v1.2.0
+
+example.com/main
+example.com/a@v1.0.0
+example.com/b@v1.0.0
+example.com/c@v1.1.0
+`,
+		},
+		{
+			desc: "main module",
+			modFile: `module example.com/main
+
+go 1.21
+`,
+			path: "example.com/main",
+			expected: &Explanation{
+				Path:  "example.com/main",
+				Paths: [][]module.Version{{mainModule}},
+			},
+			text: `# example.com/main
+example.com/main
+`,
+		},
+		{
+			desc: "not in the graph",
+			modFile: `module example.com/main
+
+go 1.21
+
+require example.com/a v1.0.0
+`,
+			path:     "example.com/unknown",
+			expected: &Explanation{Path: "example.com/unknown"},
+			text: `# example.com/unknown
+(main module does not need module example.com/unknown)
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, _ := setupProxy(t)
+
+			graph, err := LoadModFile(t.Context(), client, parseModFile(t, test.modFile))
+			require.NoError(t, err)
+
+			explanation := graph.Why(test.path)
+
+			assert.Equal(t, test.expected, explanation)
+			assert.Equal(t, test.text, explanation.String())
+		})
+	}
+}
@@ -0,0 +1,296 @@
+// Package proxyserver A server of the Go module proxy protocol (GOPROXY).
+// https://go.dev/ref/mod#goproxy-protocol
+package proxyserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ldez/grignotin/internal/modver"
+	"golang.org/x/mod/module"
+)
+
+// Storage provides the content served by the proxy.
+//
+// The module paths and the versions are not escaped.
+// The methods must return an error matching [fs.ErrNotExist] when the content does not exist.
+type Storage interface {
+	// List returns the versions of the module.
+	List(ctx context.Context, modulePath string) ([]string, error)
+
+	// Info returns the JSON-encoded information of the module version (.info file).
+	Info(ctx context.Context, modulePath, version string) ([]byte, error)
+
+	// Mod returns the go.mod file of the module version (.mod file).
+	Mod(ctx context.Context, modulePath, version string) ([]byte, error)
+
+	// Zip returns the archive file of the module version (.zip file).
+	// If the ReadCloser implements [io.Seeker], range requests are supported.
+	Zip(ctx context.Context, modulePath, version string) (io.ReadCloser, error)
+}
+
+// LatestStorage is implemented by the storages providing the latest version of a module.
+// If a storage does not implement it (or returns an error matching [fs.ErrNotExist]),
+// the latest version is the highest release version of the list,
+// or the highest pre-release version if there is no release version.
+type LatestStorage interface {
+	// Latest returns the JSON-encoded information of the latest version of the module (@latest).
+	Latest(ctx context.Context, modulePath string) ([]byte, error)
+}
+
+// Handler serves the module proxy protocol:
+//
+//	<module>/@v/list
+//	<module>/@v/<version>.info
+//	<module>/@v/<version>.mod
+//	<module>/@v/<version>.zip
+//	<module>/@latest
+//
+// The paths are relative to the root of the handler (see [http.StripPrefix]).
+type Handler struct {
+	storage Storage
+
+	// OnError is called when the storage returns an unexpected error (HTTP 500).
+	OnError func(req *http.Request, err error)
+}
+
+// NewHandler Creates a new Handler.
+func NewHandler(storage Storage) *Handler {
+	return &Handler{storage: storage}
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		rw.Header().Set("Allow", "GET, HEAD")
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	r, err := parseRequest(req.URL.Path)
+	if errors.Is(err, errInvalidPath) {
+		// The go command falls back to the next proxy only on 404 and 410 (e.g. for <proxy>/sumdb/<name>/supported).
+		http.NotFound(rw, req)
+		return
+	}
+
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.kind {
+	case "list":
+		err = h.serveList(rw, req, r)
+
+	case "latest":
+		err = h.serveLatest(rw, req, r)
+
+	case "info":
+		err = h.serveInfo(rw, req, r)
+
+	case "mod":
+		err = h.serveMod(rw, req, r)
+
+	case "zip":
+		err = h.serveZip(rw, req, r)
+	}
+
+	if err != nil {
+		h.error(rw, req, r, err)
+	}
+}
+
+func (h *Handler) serveList(rw http.ResponseWriter, req *http.Request, r *request) error {
+	versions, err := h.storage.List(req.Context(), r.modulePath)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	for _, v := range versions {
+		buf.WriteString(v)
+		buf.WriteString("\n")
+	}
+
+	h.write(rw, req, "text/plain; charset=utf-8", buf.Bytes())
+
+	return nil
+}
+
+func (h *Handler) serveLatest(rw http.ResponseWriter, req *http.Request, r *request) error {
+	data, err := h.latest(req.Context(), r.modulePath)
+	if err != nil {
+		return err
+	}
+
+	h.write(rw, req, "application/json", data)
+
+	return nil
+}
+
+func (h *Handler) serveInfo(rw http.ResponseWriter, req *http.Request, r *request) error {
+	data, err := h.storage.Info(req.Context(), r.modulePath, r.version)
+	if err != nil {
+		return err
+	}
+
+	h.write(rw, req, "application/json", data)
+
+	return nil
+}
+
+func (h *Handler) serveMod(rw http.ResponseWriter, req *http.Request, r *request) error {
+	data, err := h.storage.Mod(req.Context(), r.modulePath, r.version)
+	if err != nil {
+		return err
+	}
+
+	h.write(rw, req, "text/plain; charset=utf-8", data)
+
+	return nil
+}
+
+// serveZip serves the archive, with the support of the range requests if the storage provides an [io.Seeker].
+func (h *Handler) serveZip(rw http.ResponseWriter, req *http.Request, r *request) error {
+	body, err := h.storage.Zip(req.Context(), r.modulePath, r.version)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = body.Close() }()
+
+	rw.Header().Set("Content-Type", "application/zip")
+
+	if rs, ok := body.(io.ReadSeeker); ok {
+		http.ServeContent(rw, req, r.version+".zip", time.Time{}, rs)
+		return nil
+	}
+
+	if req.Method == http.MethodHead {
+		return nil
+	}
+
+	_, _ = io.Copy(rw, body)
+
+	return nil
+}
+
+// latest returns the information of the latest version of the module.
+func (h *Handler) latest(ctx context.Context, modulePath string) ([]byte, error) {
+	if s, ok := h.storage.(LatestStorage); ok {
+		data, err := s.Latest(ctx, modulePath)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return data, err
+		}
+	}
+
+	versions, err := h.storage.List(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	version := modver.Latest(versions)
+	if version == "" {
+		return nil, fs.ErrNotExist
+	}
+
+	return h.storage.Info(ctx, modulePath, version)
+}
+
+func (h *Handler) write(rw http.ResponseWriter, req *http.Request, contentType string, data []byte) {
+	rw.Header().Set("Content-Type", contentType)
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = rw.Write(data)
+}
+
+// error writes the response of a storage error.
+// The error is not written into the response: it can contain internal details (e.g. file paths).
+func (h *Handler) error(rw http.ResponseWriter, req *http.Request, r *request, err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		http.Error(rw, "not found: "+r.String(), http.StatusNotFound)
+		return
+	}
+
+	if h.OnError != nil {
+		h.OnError(req, err)
+	}
+
+	http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// request is a parsed request of the module proxy protocol.
+type request struct {
+	modulePath string
+	// kind is "list", "latest", "info", "mod", or "zip".
+	kind    string
+	version string
+}
+
+// String returns the requested module version: "<module>@<version>", "<module>@latest", or "<module>" for the list.
+func (r *request) String() string {
+	if r.kind == "latest" {
+		return r.modulePath + "@latest"
+	}
+
+	return module.Version{Path: r.modulePath, Version: r.version}.String()
+}
+
+var errInvalidPath = errors.New("invalid path")
+
+// parseRequest parses the path of a request of the module proxy protocol.
+func parseRequest(p string) (*request, error) {
+	p = strings.TrimPrefix(p, "/")
+
+	if escPath, ok := strings.CutSuffix(p, "/@latest"); ok {
+		modulePath, err := module.UnescapePath(escPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return &request{modulePath: modulePath, kind: "latest"}, nil
+	}
+
+	escPath, file, ok := strings.Cut(p, "/@v/")
+	if !ok {
+		return nil, errInvalidPath
+	}
+
+	modulePath, err := module.UnescapePath(escPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if file == "list" {
+		return &request{modulePath: modulePath, kind: "list"}, nil
+	}
+
+	i := strings.LastIndex(file, ".")
+	if i < 0 {
+		return nil, errInvalidPath
+	}
+
+	kind := file[i+1:]
+	if kind != "info" && kind != "mod" && kind != "zip" {
+		return nil, errInvalidPath
+	}
+
+	version, err := module.UnescapeVersion(file[:i])
+	if err != nil {
+		return nil, err
+	}
+
+	return &request{modulePath: modulePath, kind: kind, version: version}, nil
+}
@@ -0,0 +1,233 @@
+package proxyserver
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ldez/grignotin/goproxy"
+	"github.com/ldez/grignotin/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModFile = "module github.com/ldez/Grignotin\n\ngo 1.22\n"
+
+func setupMemoryStorage(t *testing.T) *MemoryStorage {
+	t.Helper()
+
+	storage := NewMemoryStorage()
+
+	date := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, version := range []string{"v0.1.0", "v0.2.0", "v0.3.0-rc.1"} {
+		archive := testutil.CreateZip(t, map[string]string{
+			"github.com/ldez/Grignotin@" + version + "/go.mod":  testModFile,
+			"github.com/ldez/Grignotin@" + version + "/LICENSE": "MIT\n",
+		})
+
+		err := storage.Add("github.com/ldez/Grignotin", version, date, []byte(testModFile), archive)
+		require.NoError(t, err)
+	}
+
+	return storage
+}
+
+func setupServer(t *testing.T, handler http.Handler) *goproxy.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return goproxy.NewClient(server.URL)
+}
+
+func TestHandler(t *testing.T) {
+	client := setupServer(t, NewHandler(setupMemoryStorage(t)))
+
+	versions, err := client.GetVersions("github.com/ldez/Grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.1.0", "v0.2.0", "v0.3.0-rc.1"}, versions)
+
+	info, err := client.GetInfo("github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.1.0", info.Version)
+	assert.Equal(t, time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), info.Time)
+
+	latest, err := client.GetLatest("github.com/ldez/Grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.2.0", latest.Version)
+
+	mod, err := client.GetModFile("github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "github.com/ldez/Grignotin", mod.Module.Mod.Path)
+
+	fsys, err := client.OpenSources("github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	content, err := fs.ReadFile(fsys, "LICENSE")
+	require.NoError(t, err)
+
+	assert.Equal(t, "MIT\n", string(content))
+
+	_, err = client.GetInfo("github.com/ldez/Grignotin", "v1.0.0")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = client.GetVersions("github.com/ldez/unknown")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	handler := NewHandler(setupMemoryStorage(t))
+
+	testCases := []struct {
+		desc        string
+		method      string
+		target      string
+		statusCode  int
+		contentType string
+		body        string
+	}{
+		{
+			desc:        "list",
+			method:      http.MethodGet,
+			target:      "/github.com/ldez/!grignotin/@v/list",
+			statusCode:  http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+		},
+		{
+			desc:        "zip",
+			method:      http.MethodGet,
+			target:      "/github.com/ldez/!grignotin/@v/v0.1.0.zip",
+			statusCode:  http.StatusOK,
+			contentType: "application/zip",
+		},
+		{
+			desc:        "head",
+			method:      http.MethodHead,
+			target:      "/github.com/ldez/!grignotin/@v/v0.1.0.info",
+			statusCode:  http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			desc:       "not escaped",
+			method:     http.MethodGet,
+			target:     "/github.com/ldez/Grignotin/@v/list",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			desc:       "unknown endpoint",
+			method:     http.MethodGet,
+			target:     "/github.com/ldez/!grignotin/@v/v0.1.0.txt",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			desc:       "checksum database",
+			method:     http.MethodGet,
+			target:     "/sumdb/sum.golang.org/supported",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			desc:       "unknown version",
+			method:     http.MethodGet,
+			target:     "/github.com/ldez/!grignotin/@v/v1.0.0.mod",
+			statusCode: http.StatusNotFound,
+			body:       "not found: github.com/ldez/Grignotin@v1.0.0\n",
+		},
+		{
+			desc:       "unknown module",
+			method:     http.MethodGet,
+			target:     "/github.com/ldez/unknown/@latest",
+			statusCode: http.StatusNotFound,
+			body:       "not found: github.com/ldez/unknown@latest\n",
+		},
+		{
+			desc:       "method not allowed",
+			method:     http.MethodPost,
+			target:     "/github.com/ldez/!grignotin/@v/list",
+			statusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, test.target, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.statusCode, rec.Code)
+
+			if test.contentType != "" {
+				assert.Equal(t, test.contentType, rec.Header().Get("Content-Type"))
+			}
+
+			if test.body != "" {
+				assert.Equal(t, test.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_notFound(t *testing.T) {
+	dir := t.TempDir()
+
+	handler := NewHandler(NewDirStorage(dir))
+
+	req := httptest.NewRequest(http.MethodGet, "/github.com/ldez/!grignotin/@v/list", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "not found: github.com/ldez/Grignotin\n", rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), dir)
+}
+
+func Test_parseRequest(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		path     string
+		expected *request
+	}{
+		{
+			desc:     "list",
+			path:     "/github.com/ldez/!grignotin/@v/list",
+			expected: &request{modulePath: "github.com/ldez/Grignotin", kind: "list"},
+		},
+		{
+			desc:     "latest",
+			path:     "/github.com/ldez/grignotin/@latest",
+			expected: &request{modulePath: "github.com/ldez/grignotin", kind: "latest"},
+		},
+		{
+			desc:     "info",
+			path:     "/github.com/ldez/grignotin/@v/v1.0.0-!r!c.1.info",
+			expected: &request{modulePath: "github.com/ldez/grignotin", kind: "info", version: "v1.0.0-RC.1"},
+		},
+		{
+			desc:     "zip",
+			path:     "/github.com/ldez/grignotin/@v/v1.0.0.zip",
+			expected: &request{modulePath: "github.com/ldez/grignotin", kind: "zip", version: "v1.0.0"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := parseRequest(test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, r)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package proxyserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ldez/grignotin/goproxy"
+	"github.com/ldez/grignotin/modcache"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// DirStorage is a Storage backed by a directory in the proxy layout:
+//
+//	<dir>/<escaped module>/@v/list
+//	<dir>/<escaped module>/@v/<escaped version>.info
+//	<dir>/<escaped module>/@v/<escaped version>.mod
+//	<dir>/<escaped module>/@v/<escaped version>.zip
+//	<dir>/<escaped module>/@latest (optional)
+type DirStorage struct {
+	dir string
+}
+
+// NewDirStorage Creates a new DirStorage.
+func NewDirStorage(dir string) *DirStorage {
+	return &DirStorage{dir: dir}
+}
+
+// NewModCacheStorage Creates a new DirStorage backed by the download directory of a module cache.
+func NewModCacheStorage(cache *modcache.Cache) *DirStorage {
+	return NewDirStorage(filepath.Join(cache.Dir, "cache", "download"))
+}
+
+// List returns the versions of the module.
+func (s *DirStorage) List(_ context.Context, modulePath string) ([]string, error) {
+	file, err := s.modulePath(modulePath, "@v", "list")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(data)), nil
+}
+
+// Latest returns the content of the "@latest" file.
+func (s *DirStorage) Latest(_ context.Context, modulePath string) ([]byte, error) {
+	file, err := s.modulePath(modulePath, "@latest")
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(file)
+}
+
+// Info returns the JSON-encoded information of the module version.
+func (s *DirStorage) Info(_ context.Context, modulePath, version string) ([]byte, error) {
+	file, err := s.versionPath(modulePath, version, "info")
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(file)
+}
+
+// Mod returns the go.mod file of the module version.
+func (s *DirStorage) Mod(_ context.Context, modulePath, version string) ([]byte, error) {
+	file, err := s.versionPath(modulePath, version, "mod")
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(file)
+}
+
+// Zip returns the archive file of the module version.
+func (s *DirStorage) Zip(_ context.Context, modulePath, version string) (io.ReadCloser, error) {
+	file, err := s.versionPath(modulePath, version, "zip")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (s *DirStorage) modulePath(modulePath string, elem ...string) (string, error) {
+	escPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+
+	return filepath.Join(append([]string{s.dir, filepath.FromSlash(escPath)}, elem...)...), nil
+}
+
+func (s *DirStorage) versionPath(modulePath, version, ext string) (string, error) {
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+
+	return s.modulePath(modulePath, "@v", escVersion+"."+ext)
+}
+
+// MemoryStorage is an in-memory Storage.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	modules map[string]map[string]*memoryVersion
+}
+
+type memoryVersion struct {
+	info []byte
+	mod  []byte
+	zip  []byte
+}
+
+// NewMemoryStorage Creates a new MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{modules: make(map[string]map[string]*memoryVersion)}
+}
+
+// Add adds a module version.
+func (s *MemoryStorage) Add(modulePath, version string, date time.Time, mod, zip []byte) error {
+	err := module.Check(modulePath, version)
+	if err != nil {
+		return err
+	}
+
+	info, err := (&goproxy.VersionInfo{Version: version, Time: date}).MarshalInfo()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.modules[modulePath] == nil {
+		s.modules[modulePath] = make(map[string]*memoryVersion)
+	}
+
+	s.modules[modulePath][version] = &memoryVersion{info: info, mod: mod, zip: zip}
+
+	return nil
+}
+
+// List returns the versions of the module.
+func (s *MemoryStorage) List(_ context.Context, modulePath string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions, ok := s.modules[modulePath]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", modulePath, fs.ErrNotExist)
+	}
+
+	list := make([]string, 0, len(versions))
+	for v := range versions {
+		list = append(list, v)
+	}
+
+	semver.Sort(list)
+
+	return list, nil
+}
+
+// Info returns the JSON-encoded information of the module version.
+func (s *MemoryStorage) Info(_ context.Context, modulePath, version string) ([]byte, error) {
+	v, err := s.get(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.info, nil
+}
+
+// Mod returns the go.mod file of the module version.
+func (s *MemoryStorage) Mod(_ context.Context, modulePath, version string) ([]byte, error) {
+	v, err := s.get(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.mod, nil
+}
+
+// Zip returns the archive file of the module version.
+func (s *MemoryStorage) Zip(_ context.Context, modulePath, version string) (io.ReadCloser, error) {
+	v, err := s.get(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return readSeekNopCloser{Reader: bytes.NewReader(v.zip)}, nil
+}
+
+func (s *MemoryStorage) get(modulePath, version string) (*memoryVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.modules[modulePath][version]
+	if !ok {
+		return nil, fmt.Errorf("%s@%s: %w", modulePath, version, fs.ErrNotExist)
+	}
+
+	return v, nil
+}
+
+// readSeekNopCloser allows range requests on an in-memory content.
+type readSeekNopCloser struct {
+	*bytes.Reader
+}
+
+func (readSeekNopCloser) Close() error { return nil }
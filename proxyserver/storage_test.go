@@ -0,0 +1,103 @@
+package proxyserver
+
+import (
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ldez/grignotin/modcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModCacheStorage(t *testing.T) {
+	upstream := setupServer(t, NewHandler(setupMemoryStorage(t)))
+
+	cache := &modcache.Cache{Dir: t.TempDir(), ReadWrite: true}
+
+	_, err := cache.Download(upstream, "github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	client := setupServer(t, NewHandler(NewModCacheStorage(cache)))
+
+	versions, err := client.GetVersions("github.com/ldez/Grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.1.0"}, versions)
+
+	latest, err := client.GetLatest("github.com/ldez/Grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.1.0", latest.Version)
+
+	fsys, err := client.OpenSources("github.com/ldez/Grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	content, err := fs.ReadFile(fsys, "go.mod")
+	require.NoError(t, err)
+
+	assert.Equal(t, testModFile, string(content))
+
+	_, err = client.GetModFile("github.com/ldez/Grignotin", "v0.2.0")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestUpstreamStorage(t *testing.T) {
+	var calls atomic.Int64
+
+	handler := NewHandler(setupMemoryStorage(t))
+
+	upstream := setupServer(t, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+
+		// The branch "master" is at v0.1.0.
+		req.URL.Path = strings.Replace(req.URL.Path, "/@v/master.", "/@v/v0.1.0.", 1)
+
+		handler.ServeHTTP(rw, req)
+	}))
+
+	cacheDir := t.TempDir()
+
+	client := setupServer(t, NewHandler(NewUpstreamStorage(upstream, cacheDir)))
+
+	for range 2 {
+		info, err := client.GetInfo("github.com/ldez/Grignotin", "v0.1.0")
+		require.NoError(t, err)
+
+		assert.Equal(t, "v0.1.0", info.Version)
+
+		mod, err := client.GetModFile("github.com/ldez/Grignotin", "v0.1.0")
+		require.NoError(t, err)
+
+		assert.Equal(t, "github.com/ldez/Grignotin", mod.Module.Mod.Path)
+
+		_, err = client.GetSources("github.com/ldez/Grignotin", "v0.1.0")
+		require.NoError(t, err)
+	}
+
+	// The module versions are cached.
+	assert.Equal(t, int64(3), calls.Load())
+
+	// The queries are always forwarded.
+	for range 2 {
+		info, err := client.GetInfo("github.com/ldez/Grignotin", "master")
+		require.NoError(t, err)
+
+		assert.Equal(t, "v0.1.0", info.Version)
+	}
+
+	assert.Equal(t, int64(5), calls.Load())
+	assert.NoFileExists(t, filepath.Join(cacheDir, "github.com", "ldez", "!grignotin", "@v", "master.info"))
+	assert.FileExists(t, filepath.Join(cacheDir, "github.com", "ldez", "!grignotin", "@v", "v0.1.0.info"))
+
+	latest, err := client.GetLatest("github.com/ldez/Grignotin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.2.0", latest.Version)
+
+	_, err = client.GetInfo("github.com/ldez/Grignotin", "v1.0.0")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -0,0 +1,127 @@
+package proxyserver
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	"github.com/ldez/grignotin/goproxy"
+	"github.com/ldez/grignotin/internal/fsutil"
+	"github.com/ldez/grignotin/internal/modver"
+)
+
+// UpstreamStorage is a Storage that forwards the requests to another proxy.
+//
+// The module versions are immutable, so the .info, .mod, and .zip files of the canonical versions can be cached inside a directory (proxy layout).
+// The list, the latest version, and the other queries (branches, commits, ...) are always forwarded.
+type UpstreamStorage struct {
+	client *goproxy.Client
+	cache  *DirStorage
+}
+
+// NewUpstreamStorage Creates a new UpstreamStorage.
+// If cacheDir is empty, nothing is cached.
+func NewUpstreamStorage(client *goproxy.Client, cacheDir string) *UpstreamStorage {
+	s := &UpstreamStorage{client: client}
+
+	if cacheDir != "" {
+		s.cache = NewDirStorage(cacheDir)
+	}
+
+	return s
+}
+
+// List returns the versions of the module.
+func (s *UpstreamStorage) List(ctx context.Context, modulePath string) ([]string, error) {
+	return s.client.GetVersionsWithContext(ctx, modulePath)
+}
+
+// Latest returns the JSON-encoded information of the latest version of the module.
+func (s *UpstreamStorage) Latest(ctx context.Context, modulePath string) ([]byte, error) {
+	info, err := s.client.GetLatestWithContext(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.MarshalInfo()
+}
+
+// Info returns the JSON-encoded information of the module version.
+func (s *UpstreamStorage) Info(ctx context.Context, modulePath, version string) ([]byte, error) {
+	return s.cached(modulePath, version, "info", func() ([]byte, error) {
+		info, err := s.client.GetInfoWithContext(ctx, modulePath, version)
+		if err != nil {
+			return nil, err
+		}
+
+		return info.MarshalInfo()
+	})
+}
+
+// Mod returns the go.mod file of the module version.
+func (s *UpstreamStorage) Mod(ctx context.Context, modulePath, version string) ([]byte, error) {
+	return s.cached(modulePath, version, "mod", func() ([]byte, error) {
+		return s.client.GetRawModFileWithContext(ctx, modulePath, version)
+	})
+}
+
+// Zip returns the archive file of the module version.
+func (s *UpstreamStorage) Zip(ctx context.Context, modulePath, version string) (io.ReadCloser, error) {
+	if s.cache == nil || !modver.IsCanonical(version) {
+		return s.client.DownloadSourcesWithContext(ctx, modulePath, version)
+	}
+
+	body, err := s.cache.Zip(ctx, modulePath, version)
+	if err == nil {
+		return body, nil
+	}
+
+	file, err := s.cache.versionPath(modulePath, version, "zip")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = s.client.DownloadSourcesWithContext(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = body.Close() }()
+
+	err = fsutil.WriteFile(file, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cache.Zip(ctx, modulePath, version)
+}
+
+// cached reads a file from the cache, or gets it and writes it into the cache.
+func (s *UpstreamStorage) cached(modulePath, version, ext string, get func() ([]byte, error)) ([]byte, error) {
+	if s.cache == nil || !modver.IsCanonical(version) {
+		return get()
+	}
+
+	file, err := s.cache.versionPath(modulePath, version, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(file)
+	if err == nil {
+		return data, nil
+	}
+
+	data, err = get()
+	if err != nil {
+		return nil, err
+	}
+
+	err = fsutil.WriteFile(file, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,210 @@
+package sumdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ldez/grignotin/internal/fsutil"
+	"github.com/ldez/grignotin/internal/proxylist"
+	"golang.org/x/mod/sumdb"
+)
+
+// clientOps implements [sumdb.ClientOps].
+type clientOps struct {
+	name   string
+	key    string
+	direct *url.URL
+
+	baseOnce sync.Once
+	base     *url.URL
+	baseErr  error
+
+	proxies    []proxylist.Proxy
+	httpClient *http.Client
+
+	configDir string
+	cacheDir  string
+
+	mu          sync.Mutex
+	config      map[string][]byte
+	cache       map[string][]byte
+	securityErr string
+}
+
+// ReadRemote reads the content served at the given path on the checksum database.
+func (o *clientOps) ReadRemote(path string) ([]byte, error) {
+	o.baseOnce.Do(o.initBase)
+
+	if o.baseErr != nil {
+		return nil, o.baseErr
+	}
+
+	return o.get(o.base.JoinPath(path))
+}
+
+// initBase finds how to access the checksum database:
+// the URL defined by GOSUMDB, the first proxy supporting the database, or directly.
+func (o *clientOps) initBase() {
+	if o.base != nil {
+		return
+	}
+
+	for _, p := range o.proxies {
+		// "direct" and "off" stop the list.
+		if p.URL == nil {
+			break
+		}
+
+		_, err := o.get(p.URL.JoinPath("sumdb", o.name, "supported"))
+		if err == nil {
+			o.base = p.URL.JoinPath("sumdb", o.name)
+			return
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) && !p.FallBackOnError {
+			o.baseErr = err
+			return
+		}
+	}
+
+	o.base = o.direct
+}
+
+func (o *clientOps) get(endpoint *url.URL) ([]byte, error) {
+	if endpoint.Scheme == "file" {
+		name, err := proxylist.FileURLToPath(endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		return os.ReadFile(name)
+	}
+
+	resp, err := o.httpClient.Get(endpoint.String()) //nolint:noctx // the sumdb.ClientOps interface has no context.
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		err = fmt.Errorf("%s: %s: %s", endpoint.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+			return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+		}
+
+		return nil, err
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// ReadConfig reads the content of the named configuration file.
+func (o *clientOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(o.key), nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	data, err := o.readFile(o.configDir, o.config, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Starts with an empty signed tree.
+		return []byte{}, nil
+	}
+
+	return data, err
+}
+
+// WriteConfig updates the content of the named configuration file.
+func (o *clientOps) WriteConfig(file string, old, data []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	current, err := o.readFile(o.configDir, o.config, file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if !bytes.Equal(current, old) {
+		return sumdb.ErrWriteConflict
+	}
+
+	return o.writeFile(o.configDir, o.config, file, data)
+}
+
+// ReadCache reads the content of the named cache file.
+func (o *clientOps) ReadCache(file string) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.readFile(o.cacheDir, o.cache, file)
+}
+
+// WriteCache writes the named cache file.
+func (o *clientOps) WriteCache(file string, data []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	_ = o.writeFile(o.cacheDir, o.cache, file, data)
+}
+
+// Log prints the given log message.
+func (o *clientOps) Log(string) {}
+
+// SecurityError keeps the security error message: it is added to the error returned by the client.
+func (o *clientOps) SecurityError(msg string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.securityErr = msg
+}
+
+func (o *clientOps) wrapError(err error) error {
+	if !errors.Is(err, ErrSecurity) {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.securityErr == "" {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", err, o.securityErr)
+}
+
+func (o *clientOps) readFile(dir string, mem map[string][]byte, file string) ([]byte, error) {
+	if dir == "" {
+		data, ok := mem[file]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+
+		return data, nil
+	}
+
+	return os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+}
+
+func (o *clientOps) writeFile(dir string, mem map[string][]byte, file string, data []byte) error {
+	if dir == "" {
+		mem[file] = bytes.Clone(data)
+		return nil
+	}
+
+	return fsutil.WriteFile(filepath.Join(dir, filepath.FromSlash(file)), bytes.NewReader(data))
+}
@@ -0,0 +1,377 @@
+// Package sumdb A client for the Go checksum database.
+// https://go.dev/ref/mod#checksum-database
+// https://go.googlesource.com/proposal/+/master/design/25530-sumdb.md
+package sumdb
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ldez/grignotin/goenv"
+	"github.com/ldez/grignotin/internal/proxylist"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/mod/sumdb/tlog"
+)
+
+// DefaultGOSUMDB is the default checksum database.
+const DefaultGOSUMDB = "sum.golang.org"
+
+// knownGOSUMDB contains the public keys of the known checksum databases.
+var knownGOSUMDB = map[string]string{
+	"sum.golang.org": "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8",
+}
+
+// ErrOff is returned when the checksum database is disabled by GOSUMDB=off.
+var ErrOff = errors.New("checksum database disabled by GOSUMDB=off")
+
+// ErrGONOSUMDB is returned by [Client.Lookup] for the module paths matching GONOSUMDB (or GOPRIVATE).
+var ErrGONOSUMDB = sumdb.ErrGONOSUMDB
+
+// ErrSecurity is returned when the checksum database misbehaves (e.g. inconsistent signed tree heads).
+var ErrSecurity = sumdb.ErrSecurity
+
+// Config is the configuration of a [Client].
+type Config struct {
+	// GOSUMDB is the name of checksum database to use and optionally its public key and URL
+	// (e.g. "sum.golang.org", "sum.golang.org+<key> https://sum.example.com").
+	// The default value is "sum.golang.org".
+	GOSUMDB string
+
+	// GONOSUMDB is a comma-separated list of glob patterns of module path prefixes
+	// that must not be checked against the checksum database.
+	// The default value is GOPRIVATE.
+	GONOSUMDB string
+
+	// GOPRIVATE is a comma-separated list of glob patterns of private module path prefixes.
+	GOPRIVATE string
+
+	// GOPROXY is the list of proxies used to access the checksum database
+	// (<proxy>/sumdb/<name>/supported), unless GOSUMDB defines a URL.
+	GOPROXY string
+
+	// ConfigDir is the directory where the latest known signed tree head is stored.
+	// If empty, it is only kept in memory.
+	ConfigDir string
+
+	// CacheDir is the directory where the lookups and the tiles are cached.
+	// If empty, they are only kept in memory.
+	CacheDir string
+
+	// HTTPClient is the HTTP client used to access the checksum database.
+	HTTPClient *http.Client
+}
+
+// ConfigFromEnv creates a Config from the configuration of the go command (go env):
+// GOSUMDB, GONOSUMDB, GOPRIVATE, and GOPROXY.
+func ConfigFromEnv(ctx context.Context) (Config, error) {
+	values, err := goenv.Get(ctx, goenv.GOSUMDB, goenv.GONOSUMDB, goenv.GOPRIVATE, goenv.GOPROXY)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		GOSUMDB:   values[goenv.GOSUMDB],
+		GONOSUMDB: values[goenv.GONOSUMDB],
+		GOPRIVATE: values[goenv.GOPRIVATE],
+		GOPROXY:   values[goenv.GOPROXY],
+	}, nil
+}
+
+// Client is a client of a checksum database.
+type Client struct {
+	name     string
+	verifier note.Verifier
+
+	ops    *clientOps
+	client *sumdb.Client
+}
+
+// NewClient creates a new Client.
+func NewClient(cfg Config) (*Client, error) {
+	db, err := parseGOSUMDB(cfg.GOSUMDB)
+	if err != nil {
+		return nil, err
+	}
+
+	var proxies []proxylist.Proxy
+
+	if cfg.GOPROXY != "" {
+		proxies, err = proxylist.Parse(cfg.GOPROXY)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GOPROXY: %w", err)
+		}
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	ops := &clientOps{
+		name:       db.name,
+		key:        db.key,
+		direct:     db.direct,
+		base:       db.base,
+		proxies:    proxies,
+		httpClient: httpClient,
+		configDir:  cfg.ConfigDir,
+		cacheDir:   cfg.CacheDir,
+		config:     make(map[string][]byte),
+		cache:      make(map[string][]byte),
+	}
+
+	client := sumdb.NewClient(ops)
+
+	if noSumDB := cmp.Or(cfg.GONOSUMDB, cfg.GOPRIVATE); noSumDB != "" {
+		client.SetGONOSUMDB(noSumDB)
+	}
+
+	return &Client{
+		name:     db.name,
+		verifier: db.verifier,
+		ops:      ops,
+		client:   client,
+	}, nil
+}
+
+// Name returns the name of the checksum database.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Lookup returns the go.sum lines (module zip and go.mod hashes) for the module version.
+// The record and the signed tree head are verified (signature and inclusion proof).
+func (c *Client) Lookup(modulePath, version string) ([]string, error) {
+	lines, err := c.lookup(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	modLines, err := c.lookup(modulePath, version+"/go.mod")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(lines, modLines...), nil
+}
+
+func (c *Client) lookup(modulePath, version string) ([]string, error) {
+	lines, err := c.client.Lookup(modulePath, version)
+	if err != nil {
+		return nil, c.ops.wrapError(err)
+	}
+
+	return lines, nil
+}
+
+// Latest fetches the latest signed tree head of the checksum database.
+//
+// The signature is verified, and the tree must be consistent with the latest known tree
+// (the consistency proof is verified with the tiles of the database).
+// The latest known tree is then updated.
+func (c *Client) Latest() (tlog.Tree, error) {
+	msg, err := c.ops.ReadRemote("/latest")
+	if err != nil {
+		return tlog.Tree{}, err
+	}
+
+	tree, err := c.openTree(msg)
+	if err != nil {
+		return tlog.Tree{}, err
+	}
+
+	file := c.name + "/latest"
+
+	knownMsg, err := c.ops.ReadConfig(file)
+	if err != nil {
+		return tlog.Tree{}, err
+	}
+
+	if len(knownMsg) > 0 {
+		known, err := c.openTree(knownMsg)
+		if err != nil {
+			return tlog.Tree{}, err
+		}
+
+		switch {
+		case known.N > tree.N:
+			err = c.checkTrees(tree, known)
+		default:
+			err = c.checkTrees(known, tree)
+		}
+
+		if err != nil {
+			return tlog.Tree{}, err
+		}
+
+		if known.N >= tree.N {
+			return known, nil
+		}
+	}
+
+	err = c.ops.WriteConfig(file, knownMsg, msg)
+	if err != nil && !errors.Is(err, sumdb.ErrWriteConflict) {
+		return tlog.Tree{}, err
+	}
+
+	return tree, nil
+}
+
+// openTree verifies the signature of a signed tree head and parses it.
+func (c *Client) openTree(msg []byte) (tlog.Tree, error) {
+	n, err := note.Open(msg, note.VerifierList(c.verifier))
+	if err != nil {
+		return tlog.Tree{}, fmt.Errorf("verifying tree note: %w", err)
+	}
+
+	tree, err := tlog.ParseTree([]byte(n.Text))
+	if err != nil {
+		return tlog.Tree{}, fmt.Errorf("parsing tree: %w", err)
+	}
+
+	return tree, nil
+}
+
+// checkTrees checks that the newer tree contains the older tree.
+func (c *Client) checkTrees(older, newer tlog.Tree) error {
+	if older.N == newer.N {
+		if older.Hash != newer.Hash {
+			return fmt.Errorf("%w: conflicting trees of size %d", ErrSecurity, older.N)
+		}
+
+		return nil
+	}
+
+	if older.N == 0 {
+		return nil
+	}
+
+	reader := tlog.TileHashReader(newer, &tileReader{ops: c.ops})
+
+	proof, err := tlog.ProveTree(newer.N, older.N, reader)
+	if err != nil {
+		return fmt.Errorf("proving tree: %w", err)
+	}
+
+	err = tlog.CheckTree(proof, newer.N, newer.Hash, older.N, older.Hash)
+	if err != nil {
+		return fmt.Errorf("%w: inconsistent trees %d and %d: %w", ErrSecurity, older.N, newer.N, err)
+	}
+
+	return nil
+}
+
+// database is a checksum database defined by GOSUMDB.
+type database struct {
+	name     string
+	key      string
+	verifier note.Verifier
+
+	// direct is the URL of the database derived from its name.
+	direct *url.URL
+	// base is the URL explicitly defined by GOSUMDB (nil if not defined).
+	base *url.URL
+}
+
+// parseGOSUMDB parses a GOSUMDB value.
+func parseGOSUMDB(gosumdb string) (*database, error) {
+	gosumdb = cmp.Or(strings.TrimSpace(gosumdb), DefaultGOSUMDB)
+
+	if gosumdb == "sum.golang.google.cn" {
+		gosumdb = "sum.golang.org https://sum.golang.google.cn"
+	}
+
+	if gosumdb == "off" {
+		return nil, ErrOff
+	}
+
+	key := strings.Fields(gosumdb)
+
+	if k := knownGOSUMDB[key[0]]; k != "" {
+		key[0] = k
+	}
+
+	if len(key) > 2 {
+		return nil, errors.New("invalid GOSUMDB: too many fields")
+	}
+
+	verifier, err := note.NewVerifier(key[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid GOSUMDB: %w", err)
+	}
+
+	db := &database{
+		name:     verifier.Name(),
+		key:      key[0],
+		verifier: verifier,
+	}
+
+	// No funny business in the database name.
+	db.direct, err = url.Parse("https://" + db.name)
+	if err != nil || strings.HasSuffix(db.name, "/") || db.direct.Host == "" || db.direct.RawPath != "" ||
+		*db.direct != (url.URL{Scheme: "https", Host: db.direct.Host, Path: db.direct.Path}) {
+		return nil, fmt.Errorf("invalid sumdb name (must be host[/path]): %s", db.name)
+	}
+
+	if len(key) == 2 {
+		// Use the explicit URL, bypassing the proxies.
+		db.base, err = url.Parse(key[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid GOSUMDB URL: %w", err)
+		}
+	}
+
+	return db, nil
+}
+
+// tileReader reads the tiles of the checksum database.
+type tileReader struct {
+	ops *clientOps
+}
+
+// Height returns the height of the tiles served by the checksum database.
+func (r *tileReader) Height() int {
+	return 8
+}
+
+// ReadTiles returns the data for each requested tile.
+func (r *tileReader) ReadTiles(tiles []tlog.Tile) ([][]byte, error) {
+	data := make([][]byte, len(tiles))
+
+	for i, tile := range tiles {
+		file := r.ops.name + "/" + tile.Path()
+
+		b, err := r.ops.ReadCache(file)
+		if err == nil {
+			data[i] = b
+			continue
+		}
+
+		b, err = r.ops.ReadRemote("/" + tile.Path())
+		if err != nil {
+			// A full tile may not be available yet as a partial tile.
+			return nil, err
+		}
+
+		data[i] = b
+	}
+
+	return data, nil
+}
+
+// SaveTiles saves the verified tiles.
+func (r *tileReader) SaveTiles(tiles []tlog.Tile, data [][]byte) {
+	for i, tile := range tiles {
+		// Only full tiles are immutable.
+		if tile.W == 1<<tile.H {
+			r.ops.WriteCache(r.ops.name+"/"+tile.Path(), data[i])
+		}
+	}
+}
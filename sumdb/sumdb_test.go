@@ -0,0 +1,294 @@
+package sumdb
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+const testDBName = "sum.example.com"
+
+func setupDB(t *testing.T) (vkey string, handler http.Handler) {
+	t.Helper()
+
+	skey, vkey, err := note.GenerateKey(rand.Reader, testDBName)
+	require.NoError(t, err)
+
+	gosum := func(path, vers string) ([]byte, error) {
+		return fmt.Appendf(nil, "%[1]s %[2]s h1:zip-%[2]s=\n%[1]s %[2]s/go.mod h1:mod-%[2]s=\n", path, vers), nil
+	}
+
+	server := sumdb.NewServer(sumdb.NewTestServer(skey, gosum))
+
+	mux := http.NewServeMux()
+	for _, p := range sumdb.ServerPaths {
+		mux.Handle(p, server)
+	}
+
+	return vkey, mux
+}
+
+func TestClient_Lookup(t *testing.T) {
+	vkey, handler := setupDB(t)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(Config{GOSUMDB: vkey + " " + server.URL})
+	require.NoError(t, err)
+
+	assert.Equal(t, testDBName, client.Name())
+
+	lines, err := client.Lookup("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	expected := []string{
+		"github.com/ldez/grignotin v0.1.0 h1:zip-v0.1.0=",
+		"github.com/ldez/grignotin v0.1.0/go.mod h1:mod-v0.1.0=",
+	}
+
+	assert.Equal(t, expected, lines)
+}
+
+func TestClient_Lookup_GONOSUMDB(t *testing.T) {
+	vkey, handler := setupDB(t)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(Config{GOSUMDB: vkey + " " + server.URL, GOPRIVATE: "github.com/ldez"})
+	require.NoError(t, err)
+
+	_, err = client.Lookup("github.com/ldez/grignotin", "v0.1.0")
+	require.ErrorIs(t, err, ErrGONOSUMDB)
+}
+
+func TestClient_Lookup_proxy(t *testing.T) {
+	vkey, handler := setupDB(t)
+
+	var supported atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sumdb/"+testDBName+"/supported", func(http.ResponseWriter, *http.Request) {
+		supported.Add(1)
+	})
+	mux.Handle("/sumdb/"+testDBName+"/", http.StripPrefix("/sumdb/"+testDBName, handler))
+
+	notSupported := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(notSupported.Close)
+
+	proxy := httptest.NewServer(mux)
+	t.Cleanup(proxy.Close)
+
+	client, err := NewClient(Config{GOSUMDB: vkey, GOPROXY: notSupported.URL + "," + proxy.URL + ",direct"})
+	require.NoError(t, err)
+
+	lines, err := client.Lookup("github.com/ldez/grignotin", "v0.1.0")
+	require.NoError(t, err)
+
+	assert.Len(t, lines, 2)
+	assert.Equal(t, int32(1), supported.Load())
+}
+
+func TestClient_Latest(t *testing.T) {
+	vkey, handler := setupDB(t)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := Config{GOSUMDB: vkey + " " + server.URL, ConfigDir: t.TempDir(), CacheDir: t.TempDir()}
+
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	for i := range 300 {
+		_, err = client.Lookup("github.com/ldez/grignotin", fmt.Sprintf("v0.%d.0", i))
+		require.NoError(t, err)
+
+		if i == 10 {
+			tree, err := client.Latest()
+			require.NoError(t, err)
+
+			assert.EqualValues(t, 11, tree.N)
+		}
+	}
+
+	// A new client reads the latest known tree from the configuration directory,
+	// and checks the consistency with the new tree.
+	client, err = NewClient(cfg)
+	require.NoError(t, err)
+
+	tree, err := client.Latest()
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 300, tree.N)
+}
+
+func TestClient_Latest_invalid_signature(t *testing.T) {
+	_, handler := setupDB(t)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	_, otherKey, err := note.GenerateKey(rand.Reader, testDBName)
+	require.NoError(t, err)
+
+	client, err := NewClient(Config{GOSUMDB: otherKey + " " + server.URL})
+	require.NoError(t, err)
+
+	_, err = client.Latest()
+	require.Error(t, err)
+}
+
+func Test_parseGOSUMDB(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		gosumdb      string
+		expectedName string
+		expectedBase string
+	}{
+		{
+			desc:         "default",
+			gosumdb:      "",
+			expectedName: "sum.golang.org",
+		},
+		{
+			desc:         "known",
+			gosumdb:      "sum.golang.org",
+			expectedName: "sum.golang.org",
+		},
+		{
+			desc:         "china",
+			gosumdb:      "sum.golang.google.cn",
+			expectedName: "sum.golang.org",
+			expectedBase: "https://sum.golang.google.cn",
+		},
+		{
+			desc:         "explicit URL",
+			gosumdb:      "sum.golang.org https://sum.example.com/db",
+			expectedName: "sum.golang.org",
+			expectedBase: "https://sum.example.com/db",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			db, err := parseGOSUMDB(test.gosumdb)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedName, db.name)
+			assert.Equal(t, "https://"+test.expectedName, db.direct.String())
+
+			if test.expectedBase == "" {
+				assert.Nil(t, db.base)
+			} else {
+				assert.Equal(t, test.expectedBase, db.base.String())
+			}
+		})
+	}
+}
+
+func Test_parseGOSUMDB_error(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		gosumdb string
+	}{
+		{
+			desc:    "off",
+			gosumdb: "off",
+		},
+		{
+			desc:    "unknown database without key",
+			gosumdb: "sum.example.com",
+		},
+		{
+			desc:    "too many fields",
+			gosumdb: "sum.golang.org https://a.example https://b.example",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := parseGOSUMDB(test.gosumdb)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNewClient_proxies(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		goproxy  string
+		expected []string
+	}{
+		{
+			desc:     "separators",
+			goproxy:  "https://a.example|https://b.example, ,direct,https://d.example",
+			expected: []string{"https://a.example true", "https://b.example false", "direct false"},
+		},
+		{
+			desc:     "implicit scheme",
+			goproxy:  "corp.example/goproxy,direct",
+			expected: []string{"https://corp.example/goproxy false", "direct false"},
+		},
+		{
+			desc:     "file URL",
+			goproxy:  "file:///var/cache/download,off",
+			expected: []string{"file:///var/cache/download false", "off false"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := NewClient(Config{GOPROXY: test.goproxy})
+			require.NoError(t, err)
+
+			var actual []string
+
+			for _, p := range client.ops.proxies {
+				actual = append(actual, fmt.Sprintf("%s %t", p.Name, p.FallBackOnError))
+			}
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestNewClient_invalidProxy(t *testing.T) {
+	_, err := NewClient(Config{GOPROXY: "https://a.example,ftp://c.example"})
+	require.Error(t, err)
+}
+
+func Test_clientOps_initBase_fileProxy(t *testing.T) {
+	dir := t.TempDir()
+
+	supported := filepath.Join(dir, "sumdb", testDBName, "supported")
+	require.NoError(t, os.MkdirAll(filepath.Dir(supported), 0o755))
+	require.NoError(t, os.WriteFile(supported, nil, 0o600))
+
+	notSupported := (&url.URL{Scheme: "file", Path: filepath.ToSlash(t.TempDir())}).String()
+	proxy := (&url.URL{Scheme: "file", Path: filepath.ToSlash(dir)}).String()
+
+	vkey, _ := setupDB(t)
+
+	client, err := NewClient(Config{GOSUMDB: vkey, GOPROXY: notSupported + "," + proxy + ",direct"})
+	require.NoError(t, err)
+
+	client.ops.initBase()
+	require.NoError(t, client.ops.baseErr)
+
+	assert.Equal(t, proxy+"/sumdb/"+testDBName, client.ops.base.String())
+}